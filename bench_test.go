@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type benchParam struct {
+	Name      string    `form:"name" json:"name" valid:"required" regexp:"^[a-zA-Z_][a-zA-Z_]*$"`
+	Age       int       `form:"age" json:"age" valid:"required" range:"18|25"`
+	Passed    bool      `form:"passed" json:"passed" valid:"required"`
+	Score     float32   `form:"score" json:"score" valid:"required" min:"60.0"`
+	Area      float64   `form:"area" json:"area" valid:"required" max:"200.0"`
+	Side      string    `form:"side" json:"side" valid:"required" values:"front|back"`
+	Friends   []string  `form:"friends" json:"friends" valid:"required" regexp:"^[a-zA-Z_][a-zA-Z_]*$"`
+	Scores    []float32 `form:"scores" json:"scores" valid:"required" range:"60|100"`
+	ExtraInfo string    `form:"extra_info" json:"extra_info" valid:"optional" default:"hello"`
+}
+
+func benchmarkBind(b *testing.B, newRequest func() *http.Request) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		obj := benchParam{}
+		if err := Bind(newRequest(), &obj); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBindForm(b *testing.B) {
+	body := url.Values{}
+	body.Add("name", "ming")
+	body.Add("age", "20")
+	body.Add("passed", "true")
+	body.Add("score", "86.5")
+	body.Add("area", "148.898877383")
+	body.Add("side", "front")
+	body.Add("friends[]", "Mary")
+	body.Add("friends[]", "Jack")
+	body.Add("scores[]", "68.5")
+	body.Add("scores[]", "73.5")
+	query := "/?" + body.Encode()
+
+	benchmarkBind(b, func() *http.Request {
+		return request("GET", query, "", "")
+	})
+}
+
+func BenchmarkBindJson(b *testing.B) {
+	body := strings.Join([]string{`{"name":"ming","age":20,"passed":true,"score":86.5,"area":148.898877383,`,
+		`"side":"front","friends":["Mary","Jack"],"scores":[68.5,73.5],"extra_info":"hello"}`}, "")
+
+	benchmarkBind(b, func() *http.Request {
+		return request("POST", "/", body, ContentTypeJson)
+	})
+}
@@ -2,8 +2,15 @@ package validator
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"reflect"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
 )
 
 // MultipartMemory is the maximum permitted size of the request body in an HTTP request.
@@ -14,7 +21,8 @@ var MultipartMemory int64 = 64 * 1024 * 1024
 // Bind takes data out of the request and deserializes into a interface obj according
 // to the Content-Type of the request. If no Content-Type is specified, there
 // better be data in the query string, otherwise an error will be produced.
-// A non-nil return value may be an Errors value.
+// A non-nil return value may be an Errors value listing every param that
+// failed to bind or validate, or ErrInvalidTag if obj has malformed tags.
 func Bind(req *http.Request, obj interface{}) error {
 	method := req.Method
 	contentType := filterFlags(req.Header.Get("Content-Type"))
@@ -26,46 +34,137 @@ func Bind(req *http.Request, obj interface{}) error {
 	switch contentType {
 	case ContentTypeJson:
 		return BindJson(req, obj)
+	case ContentTypeXml, ContentTypeTextXml:
+		return BindXML(req, obj)
+	case ContentTypeYaml:
+		return BindYAML(req, obj)
+	case ContentTypeToml:
+		return BindTOML(req, obj)
 	case ContentTypeMultipart:
 		return BindMultipart(req, obj)
 	case ContentTypeForm:
 		return BindForm(req, obj)
 	default:
-		return fmt.Errorf(ERR_UNSUPPORTED_CONTENT_TYPE)
+		return ErrUnsupportedContentType
 	}
 }
 
 func BindForm(req *http.Request, obj interface{}) error {
-	if err := req.ParseForm(); err != nil {
-		return fmt.Errorf("%v: %v", ERR_PARSE_FORM, err.Error())
-	}
-	if err := coerce(obj, req.Form, nil); err != nil {
+	if err := checkTags(reflect.TypeOf(obj).Elem()); err != nil {
 		return err
 	}
-	return validate(obj, "form")
+	if err := req.ParseForm(); err != nil {
+		return fmt.Errorf("%w: %v", ErrParseForm, err)
+	}
+	return validateStructLevel(req, obj, "form", coerce(obj, req.Form, nil))
 }
 
 func BindMultipart(req *http.Request, obj interface{}) error {
-	if err := req.ParseMultipartForm(MultipartMemory); err != nil {
-		return fmt.Errorf("%v: %v", ERR_PARSE_MULTIPART_FORM, err.Error())
-	}
-	if err := coerce(obj, req.Form, req.MultipartForm.File); err != nil {
+	if err := checkTags(reflect.TypeOf(obj).Elem()); err != nil {
 		return err
 	}
-	return validate(obj, "form")
+	if err := req.ParseMultipartForm(MultipartMemory); err != nil {
+		return fmt.Errorf("%w: %v", ErrParseMultipartForm, err)
+	}
+	return validateStructLevel(req, obj, "form", coerce(obj, req.Form, req.MultipartForm.File))
 }
 
 func BindURL(req *http.Request, obj interface{}) error {
-	if err := coerce(obj, req.URL.Query(), nil); err != nil {
+	if err := checkTags(reflect.TypeOf(obj).Elem()); err != nil {
 		return err
 	}
-	return validate(obj, "form")
+	return validateStructLevel(req, obj, "form", coerce(obj, req.URL.Query(), nil))
 }
 
 func BindJson(req *http.Request, obj interface{}) error {
-	err := json.NewDecoder(req.Body).Decode(obj)
+	if err := checkTags(reflect.TypeOf(obj).Elem()); err != nil {
+		return err
+	}
+	var data json.RawMessage
+	dec := json.NewDecoder(req.Body)
+	if err := dec.Decode(&data); err != nil {
+		return fmt.Errorf("%w: %v", ErrDecodeJson, err)
+	}
+	val := reflect.ValueOf(obj).Elem()
+	var errs Errors
+	if schemaOf(val.Type()).strict {
+		if _, err := dec.Token(); err != io.EOF {
+			errs = append(errs, &FieldError{Code: CodeTrailingData, Message: ERR_TRAILING_DATA})
+		}
+	}
+	if err := decodeJson(data, val, "", false, &errs); err != nil {
+		return fmt.Errorf("%w: %v", ErrDecodeJson, err)
+	}
+	return validateStructLevel(req, obj, "json", errs)
+}
+
+// BindXML binds an xml body using the `xml` tags of obj. The root element may
+// have any name, unless obj has an `XMLName` field naming it, as for
+// encoding/xml. Child elements and `,attr` attributes are bound as json keys
+// are, and a repeated element is bound to a slice.
+func BindXML(req *http.Request, obj interface{}) error {
+	if err := checkTags(reflect.TypeOf(obj).Elem()); err != nil {
+		return err
+	}
+	var root xmlNode
+	if err := xml.NewDecoder(req.Body).Decode(&root); err != nil {
+		return fmt.Errorf("%w: %v", ErrDecodeXml, err)
+	}
+	var errs Errors
+	if err := decodeXml(&root, reflect.ValueOf(obj).Elem(), "", &errs); err != nil {
+		return fmt.Errorf("%w: %v", ErrDecodeXml, err)
+	}
+	return validateStructLevel(req, obj, "xml", errs)
+}
+
+// BindYAML binds a yaml body using the `yaml` tags of obj. Keys are bound as
+// json keys are, and errors give the line of the key, e.g.
+// `address.city: not found (line 3)`.
+func BindYAML(req *http.Request, obj interface{}) error {
+	if err := checkTags(reflect.TypeOf(obj).Elem()); err != nil {
+		return err
+	}
+	var root yaml.Node
+	if err := yaml.NewDecoder(req.Body).Decode(&root); err != nil {
+		return fmt.Errorf("%w: %v", ErrDecodeYaml, err)
+	}
+	doc := yamlDocument(&root)
+	if doc == nil {
+		doc = &document{fields: map[string]*document{}}
+	}
+	var errs Errors
+	if err := decodeDocument(doc, reflect.ValueOf(obj).Elem(), "", "yaml", &errs); err != nil {
+		return fmt.Errorf("%w: %v", ErrDecodeYaml, err)
+	}
+	return validateStructLevel(req, obj, "yaml", errs)
+}
+
+// BindTOML binds a toml body using the `toml` tags of obj, as BindYAML does.
+func BindTOML(req *http.Request, obj interface{}) error {
+	if err := checkTags(reflect.TypeOf(obj).Elem()); err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(req.Body)
 	if err != nil {
-		return fmt.Errorf("%v: %v", ERR_DECODE_JSON, err.Error())
+		return fmt.Errorf("%w: %v", ErrDecodeToml, err)
+	}
+	var root map[string]interface{}
+	if err := toml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("%w: %v", ErrDecodeToml, err)
+	}
+	var errs Errors
+	doc := tomlDocument(root, "", 0, tomlLines(data))
+	if err := decodeDocument(doc, reflect.ValueOf(obj).Elem(), "", "toml", &errs); err != nil {
+		return fmt.Errorf("%w: %v", ErrDecodeToml, err)
+	}
+	return validateStructLevel(req, obj, "toml", errs)
+}
+
+// validateStructLevel calls the Validate hooks of obj, unless errs already
+// reports invalid fields.
+func validateStructLevel(req *http.Request, obj interface{}, format string, errs Errors) error {
+	if len(errs) == 0 {
+		errs = validateHooks(req.Context(), obj, format)
 	}
-	return validate(obj, "json")
+	return errs.orNil()
 }
@@ -0,0 +1,76 @@
+package validator
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type blankParam struct {
+	Bio      string   `form:"bio" json:"bio" valid:"optional" allow_empty:"true" min_len:"10"`
+	Nickname *string  `form:"nickname" json:"nickname" valid:"optional" allow_empty:"true"`
+	Name     string   `form:"name" json:"name" valid:"required" nonblank:"trim"`
+	Tags     []string `form:"tags" json:"tags" valid:"optional" nonblank:"trim"`
+}
+
+func TestAllowEmpty(t *testing.T) {
+	body := url.Values{}
+	body.Add("bio", "")
+	body.Add("nickname", "")
+	body.Add("name", "Tony")
+
+	obj := blankParam{Bio: "old"}
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "", obj.Bio)
+	assert.Equal(t, "", *obj.Nickname)
+
+	obj = blankParam{}
+	req = request("POST", "/", `{"bio": "", "nickname": "", "name": "Tony"}`, ContentTypeJson)
+	assert.NoError(t, Bind(req, &obj))
+	assert.NotNil(t, obj.Nickname)
+
+	body.Set("bio", "short")
+	obj = blankParam{}
+	req = request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "bio: shorter than 10 characters", err.Error())
+}
+
+func TestNonblankTrim(t *testing.T) {
+	body := url.Values{}
+	body.Add("name", " \t ")
+	body.Add("tags", "go")
+	body.Add("tags", "  ")
+
+	obj := blankParam{}
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "name: blank string; tags: blank string", err.Error())
+	assert.True(t, errors.Is(err, CodeBlankString))
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "nonblank", fieldErr.Tag)
+	assert.Equal(t, "trim", fieldErr.Param)
+}
+
+type malformedBlankParam struct {
+	Name string `form:"name" valid:"required" allow_empty:"yes"`
+	Bio  string `form:"bio" valid:"required" allow_empty:"true" nonblank:"trim"`
+	Note string `form:"note" valid:"required" nonblank:"spaces"`
+	Age  int    `form:"age" valid:"required" allow_empty:"true"`
+}
+
+func TestBlankMalformedTags(t *testing.T) {
+	err := Compile(&malformedBlankParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "Name: invalid `allow_empty` tag, must be bool; "+
+		"Bio: invalid `nonblank` tag, not allowed with `allow_empty`; "+
+		"Note: invalid `nonblank` tag, must be `true` or `trim`; "+
+		"Age: invalid `allow_empty` tag, not allowed on int", err.Error())
+}
@@ -2,117 +2,314 @@ package validator
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"mime/multipart"
 	"reflect"
 	"strconv"
+	"time"
 )
 
-// coerce tries to set the value with the type of the param. If fail then return error
-func coerce(obj interface{}, formData map[string][]string, formFile map[string][]*multipart.FileHeader) error {
+// coerce tries to set the value with the type of the param, then validates
+// it. Every field that can not be set or is not valid is reported in the
+// returned Errors, and so are unknown keys in strict mode, see Strict.
+func coerce(obj interface{}, formData map[string][]string, formFile map[string][]*multipart.FileHeader) Errors {
+	var errs Errors
 	val := reflect.ValueOf(obj).Elem()
+	coerceStruct(val, schemaOf(val.Type()), formPath{}, formData, formFile, &errs)
+	checkUnknownParams(val.Type(), formData, formFile, &errs)
+	return errs
+}
 
-	for i := 0; i < val.NumField(); i++ {
-		tag := val.Type().Field(i).Tag
-		name := tag.Get("form")
-		field := val.Field(i)
+func coerceStruct(val reflect.Value, s *structSchema, path formPath, formData map[string][]string,
+	formFile map[string][]*multipart.FileHeader, errs *Errors) {
+	for _, f := range s.fields {
+		field := val.Field(f.index)
+		name, ok := f.name("form")
+		if !ok {
+			continue
+		}
 
-		err := coerceField(field, name, tag, formData, formFile)
-		if err != nil {
-			if err.Error() == ERR_OPTIONAL_PARAM_NOT_FOUND {
-				continue
-			} else {
-				return fmt.Errorf("%s: %s", name, err.Error())
+		switch f.kind {
+		case fieldNested:
+			fieldPath := path
+			if !f.embedded || name != "" {
+				fieldPath = path.child(name)
+			}
+			n := len(*errs)
+			coerceNested(field, fieldPath, f.rules, formData, formFile, errs)
+			// Registered rules of a sent struct, once its fields are valid
+			if len(*errs) == n && fieldPath != path && fieldPath.present(formData, formFile) {
+				if err := validateRules(field, f, val, fieldPath.dotted); err != nil {
+					errs.add(fieldPath.dotted, err)
+				}
+			}
+		case fieldMap:
+			fieldPath := path.child(name)
+			n := len(*errs)
+			coerceMap(field, fieldPath, f.rules, formData, errs)
+			if len(*errs) == n {
+				if err := validateRules(field, f, val, fieldPath.dotted); err != nil {
+					errs.add(fieldPath.dotted, err)
+				}
+			}
+		default:
+			fieldPath := path.child(name)
+			err := coerceField(field, fieldPath.keys(), f.rules, formData, formFile)
+			if err == nil {
+				err = validateField(field, f.rules)
+			}
+			if err == nil {
+				err = validateRules(field, f, val, fieldPath.dotted)
+			}
+			if err != nil && err.Code != codeOptionalParamNotFound {
+				errs.add(fieldPath.dotted, err)
 			}
 		}
 	}
-	return nil
+	present := func(f *fieldSchema) bool {
+		name, _ := f.name("form")
+		return path.child(name).sent(formData, formFile)
+	}
+	validateConditions(val, s, path.dotted, "form", present, errs)
+	validateComparisons(val, s, path.dotted, "form", present, errs)
 }
 
-func coerceField(val reflect.Value, name string, tag reflect.StructTag, formData map[string][]string,
-	formFile map[string][]*multipart.FileHeader) (err error) {
+// coerceNested coerces a struct or pointer-to-struct field. Pointers are only
+// allocated when at least one param of the struct is present.
+func coerceNested(val reflect.Value, path formPath, r *rules, formData map[string][]string,
+	formFile map[string][]*multipart.FileHeader, errs *Errors) {
+	if path.dotted != "" && !path.present(formData, formFile) {
+		switch r.valid {
+		case "required":
+			errs.add(path.dotted, &FieldError{Tag: "valid", Param: "required", Code: CodeParamNotFound,
+				Message: ERR_PARAM_NOT_FOUND})
+			return
+		case "optional":
+			return
+		default:
+			// Conditional fields are checked once the struct is coerced, and
+			// absent pointers are left nil
+			if r.condition != nil || val.Kind() == reflect.Ptr {
+				return
+			}
+		}
+	}
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		val = val.Elem()
+	}
+	coerceStruct(val, schemaOf(val.Type()), path, formData, formFile, errs)
+}
+
+// coerceMap coerces the params `name[key]` or `name.key` as the entries of a
+// map field, then validates it.
+func coerceMap(val reflect.Value, path formPath, r *rules, formData map[string][]string, errs *Errors) {
+	entries := path.entries(formData)
+	if len(entries) == 0 {
+		if r.valid == "required" {
+			errs.add(path.dotted, &FieldError{Tag: "valid", Param: "required", Code: CodeParamNotFound,
+				Message: ERR_PARAM_NOT_FOUND})
+		}
+		return
+	}
+
+	m := reflect.MakeMapWithSize(val.Type(), len(entries))
+	failed := false
+	for _, key := range sortedKeys(entries) {
+		k := reflect.New(val.Type().Key()).Elem()
+		v := reflect.New(val.Type().Elem()).Elem()
+		err := setParams(k, []string{key}, noRules)
+		if err == nil {
+			err = setParams(v, entries[key], r)
+		}
+		if err != nil {
+			errs.add(joinPath(path.dotted, key), err)
+			failed = true
+			continue
+		}
+		m.SetMapIndex(k, v)
+	}
+	val.Set(m)
+	if !failed {
+		validateMap(val, r, path.dotted, errs)
+	}
+}
+
+func coerceField(val reflect.Value, keys []string, r *rules, formData map[string][]string,
+	formFile map[string][]*multipart.FileHeader) *FieldError {
+	// A malformed tag could make coercion panic
+	if err := r.tagError(); err != nil {
+		return err
+	}
+
 	var params []string
 	var files []*multipart.FileHeader
-	notFound := false
 
 	// Get params and files
-	if vs := formData[name]; len(vs) > 0 {
-		params = vs
-	} else if vs := formData[name+"[]"]; len(vs) > 0 {
-		params = vs
-	} else if formFile != nil && len(formFile[name]) > 0 {
-		files = formFile[name]
-	} else {
-		notFound = true
+	for _, key := range keys {
+		if vs := formData[key]; len(vs) > 0 {
+			params = vs
+			break
+		}
+	}
+	for _, key := range keys {
+		if params == nil && formFile != nil && len(formFile[key]) > 0 {
+			files = formFile[key]
+			break
+		}
 	}
+	notFound := len(params) == 0 && len(files) == 0
 
 	// Check exist
 	if notFound {
-		switch tag.Get("valid") {
+		switch r.valid {
 		case "required":
-			return fmt.Errorf(ERR_PARAM_NOT_FOUND)
+			return &FieldError{Tag: "valid", Param: "required", Code: CodeParamNotFound, Message: ERR_PARAM_NOT_FOUND}
 		case "optional":
-			if len(tag.Get("default")) != 0 {
-				params = []string{tag.Get("default")}
-			} else {
-				return fmt.Errorf(ERR_OPTIONAL_PARAM_NOT_FOUND)
+			if len(r.defaultValue) != 0 {
+				params = []string{r.defaultValue}
+				break
 			}
+			fallthrough
+		default:
+			return &FieldError{Tag: "valid", Param: "optional", Code: codeOptionalParamNotFound,
+				Message: ERR_OPTIONAL_PARAM_NOT_FOUND}
 		}
 	}
 
-	if tag.Get("type") == "file" {
+	// Allocate pointer, it is left nil when the param is absent
+	if val.Kind() == reflect.Ptr {
+		val.Set(reflect.New(val.Type().Elem()))
+		val = val.Elem()
+	}
+
+	if r.typ == "file" {
 		// Read file to bytes
 		if len(files) > 0 {
 			f, err := files[0].Open()
 			if err != nil {
-				return fmt.Errorf(ERR_CORRUPTED_FILE)
+				return &FieldError{Tag: "type", Param: "file", Code: CodeCorruptedFile, Message: ERR_CORRUPTED_FILE}
 			}
 			blob, err := ioutil.ReadAll(f)
 			if err != nil {
-				return fmt.Errorf(ERR_CORRUPTED_FILE)
+				return &FieldError{Tag: "type", Param: "file", Code: CodeCorruptedFile, Message: ERR_CORRUPTED_FILE}
 			}
 			val.SetBytes(blob)
 		} else if len(params) > 0 {
-			return fmt.Errorf(ERR_FILE_TYPE_INVALID)
+			return &FieldError{Tag: "type", Param: "file", Value: params[0], Code: CodeFileTypeInvalid,
+				Message: ERR_FILE_TYPE_INVALID}
+		}
+	} else if r.typ == "base64" {
+		// A file is not a base64 string
+		if len(params) == 0 {
+			return &FieldError{Tag: "type", Param: "base64", Code: CodeInvalidBase64, Message: ERR_INVALID_BASE64}
 		}
-	} else if tag.Get("type") == "base64" {
 		// Decode base64 string to bytes
 		decoded, err := base64.StdEncoding.DecodeString(params[0])
 		if err != nil {
-			return fmt.Errorf(ERR_INVALID_BASE64)
+			return &FieldError{Tag: "type", Param: "base64", Value: params[0], Code: CodeInvalidBase64,
+				Message: ERR_INVALID_BASE64}
 		}
 		val.SetBytes(decoded)
 	} else {
 		// Other type
-		switch val.Kind() {
-		case reflect.Slice:
-			s := reflect.MakeSlice(val.Type(), len(params), len(params))
-			for i, v := range params {
-				err = setValue(s.Index(i), v)
-				if err != nil {
-					return fmt.Errorf(ERR_PARAM_INVALID, val.Kind().String())
-				}
-			}
-			val.Set(s)
-		default:
-			setValue(val, params[0])
-			if err != nil {
-				return fmt.Errorf(ERR_PARAM_INVALID, val.Kind().String())
+		return setParams(val, params, r)
+	}
+	return nil
+}
+
+// setParams sets val from params, all of them for a slice, otherwise the first one.
+func setParams(val reflect.Value, params []string, r *rules) *FieldError {
+	switch {
+	case val.Kind() == reflect.Slice && !isCoercible(val.Type()):
+		s := reflect.MakeSlice(val.Type(), len(params), len(params))
+		for i, v := range params {
+			if err := setValue(s.Index(i), v, r); err == errOverflow {
+				return overflowParam(s.Index(i), v)
+			} else if err != nil {
+				return invalidParam(val, v, r)
 			}
 		}
+		val.Set(s)
+	default:
+		if err := setValue(val, params[0], r); err == errOverflow {
+			return overflowParam(val, params[0])
+		} else if err != nil {
+			return invalidParam(val, params[0], r)
+		}
 	}
 	return nil
 }
 
-func setValue(val reflect.Value, param string) error {
-	switch val.Kind() {
-	case reflect.Int:
-		i, err := strconv.ParseInt(param, 10, 64)
+// invalidParam reports that param can not be coerced to the kind of val.
+func invalidParam(val reflect.Value, param string, r *rules) *FieldError {
+	switch t := elemType(val.Type()); {
+	case t == typeOfTime:
+		return invalidTime(param, r)
+	case t == typeOfDuration:
+		return &FieldError{Value: param, Code: CodeParamInvalid, Message: fmt.Sprintf(ERR_PARAM_INVALID, "duration")}
+	case isCoercible(t):
+		return &FieldError{Value: param, Code: CodeParamInvalid, Message: fmt.Sprintf(ERR_PARAM_INVALID, t.String())}
+	}
+	return &FieldError{Value: param, Code: CodeParamInvalid,
+		Message: fmt.Sprintf(ERR_PARAM_INVALID, val.Kind().String())}
+}
+
+// overflowParam reports that param is out of the range of the kind of val.
+func overflowParam(val reflect.Value, param string) *FieldError {
+	t := val.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return &FieldError{Value: param, Code: CodeParamOverflow,
+		Message: fmt.Sprintf(ERR_PARAM_OVERFLOW, param, t.Kind().String())}
+}
+
+// errOverflow is returned by setValue when param does not fit in the kind of val.
+var errOverflow = errors.New("overflow")
+
+func setValue(val reflect.Value, param string, r *rules) error {
+	if typeCoercer(val.Type()) != nil {
+		return coerceCustom(val, param)
+	}
+
+	switch val.Type() {
+	case typeOfTime:
+		t, err := parseTime(param, r.timeFormat)
+		if err != nil {
+			return err
+		}
+		val.Set(reflect.ValueOf(t))
+		return nil
+	case typeOfDuration:
+		d, err := time.ParseDuration(param)
 		if err != nil {
 			return err
 		}
+		val.SetInt(int64(d))
+		return nil
+	}
+	if isCoercible(val.Type()) {
+		return coerceCustom(val, param)
+	}
+
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(param, 10, val.Type().Bits())
+		if err != nil {
+			return numError(err)
+		}
 		val.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(param, 10, val.Type().Bits())
+		if err != nil {
+			return numError(err)
+		}
+		val.SetUint(u)
 	case reflect.Bool:
 		b, err := strconv.ParseBool(param)
 		if err != nil {
@@ -122,18 +319,32 @@ func setValue(val reflect.Value, param string) error {
 	case reflect.Float32:
 		f32, err := strconv.ParseFloat(param, 32)
 		if err != nil {
-			return err
+			return numError(err)
 		}
 		val.SetFloat(f32)
 	case reflect.Float64:
 		f64, err := strconv.ParseFloat(param, 64)
 		if err != nil {
-			return err
+			return numError(err)
 		}
 		val.SetFloat(f64)
 	case reflect.String:
-		val.SetString(param)
+		val.SetString(r.transform(param))
+	case reflect.Ptr:
+		p := reflect.New(val.Type().Elem())
+		if err := setValue(p.Elem(), param, r); err != nil {
+			return err
+		}
+		val.Set(p)
 	default:
 	}
 	return nil
 }
+
+// numError turns the out of range error of strconv into errOverflow.
+func numError(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+		return errOverflow
+	}
+	return err
+}
@@ -0,0 +1,230 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// comparisonTags compare a field to another field, named by its Go field
+// name in the same struct, or by a dotted path into a nested struct such as
+// `Price.Min`.
+var comparisonTags = []string{"eqfield", "nefield", "gtfield", "ltfield"}
+
+// comparison is a parsed comparison tag of a field.
+type comparison struct {
+	tag   string
+	param string
+	// index leads to the other field from the struct holding both.
+	index []int
+	// names holds the path of the other field for each of the formats,
+	// relative to the struct holding both.
+	names map[string]string
+}
+
+// parseComparisons parses the comparison tags of the field sf of the struct
+// type t. Malformed ones are recorded in r.
+func parseComparisons(t reflect.Type, sf reflect.StructField, r *rules) []*comparison {
+	var cs []*comparison
+	for _, tag := range comparisonTags {
+		param := sf.Tag.Get(tag)
+		if len(param) == 0 {
+			continue
+		}
+		c := &comparison{tag: tag, param: param, names: make(map[string]string, len(formats))}
+		ct := t
+		for _, name := range strings.Split(param, ".") {
+			if ct.Kind() == reflect.Ptr {
+				ct = ct.Elem()
+			}
+			var other reflect.StructField
+			ok := ct.Kind() == reflect.Struct
+			if ok {
+				other, ok = ct.FieldByName(name)
+			}
+			if !ok || other.PkgPath != "" {
+				c = nil
+				r.fail(&FieldError{Tag: tag, Param: param, Code: CodeInvalidTag,
+					Message: fmt.Sprintf(ERR_INVALID_FIELD_TAG, tag, param)})
+				break
+			}
+			c.index = append(c.index, other.Index...)
+			for _, format := range formats {
+				n := tagName(other.Tag, format)
+				if n == "" && !other.Anonymous {
+					n = other.Name
+				}
+				if n != "" {
+					c.names[format] = joinPath(c.names[format], n)
+				}
+			}
+			ct = other.Type
+		}
+		if c == nil {
+			continue
+		}
+		if !canCompare(tag, sf.Type, ct) {
+			r.fail(&FieldError{Tag: tag, Param: param, Code: CodeInvalidTag,
+				Message: fmt.Sprintf(ERR_INVALID_FIELD_TAG_TYPE, tag, sf.Type, ct)})
+			continue
+		}
+		cs = append(cs, c)
+	}
+	return cs
+}
+
+// canCompare reports whether values of types a and b, or the types they point
+// to, can be compared by tag.
+func canCompare(tag string, a, b reflect.Type) bool {
+	if a.Kind() == reflect.Ptr {
+		a = a.Elem()
+	}
+	if b.Kind() == reflect.Ptr {
+		b = b.Elem()
+	}
+	if a != b {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Bool:
+		return tag == "eqfield" || tag == "nefield"
+	}
+	return a == typeOfTime
+}
+
+// validateComparisons checks the comparison tags of the fields of the struct
+// val at path. Fields which were not sent, as told by present, fields already
+// reported in errs, and nil pointers are skipped.
+func validateComparisons(val reflect.Value, s *structSchema, path string, format string,
+	present func(f *fieldSchema) bool, errs *Errors) {
+	for _, f := range s.fields {
+		if len(f.comparisons) == 0 {
+			continue
+		}
+		if _, ok := f.name(format); !ok {
+			continue
+		}
+		fieldPath := f.path(path, format)
+		field, ok := indirect(val.Field(f.index))
+		if !ok || !present(f) || errs.has(fieldPath) {
+			continue
+		}
+
+		for _, c := range f.comparisons {
+			otherPath := joinPath(path, c.names[format])
+			other, ok := fieldByIndex(val, c.index)
+			if !ok || !s.sent(c.index, format, present) || errs.has(otherPath) {
+				continue
+			}
+			if err := compareField(field, other, c, otherPath); err != nil {
+				errs.add(fieldPath, err)
+				break
+			}
+		}
+	}
+}
+
+// sent reports whether the field at index, from the struct of s, was sent,
+// as told by present. The fields of a nested struct are known by the nested
+// struct only, so they are deemed sent along with it, as are promoted ones.
+func (s *structSchema) sent(index []int, format string, present func(f *fieldSchema) bool) bool {
+	for _, f := range s.fields {
+		if f.index != index[0] {
+			continue
+		}
+		if f.embedded && f.names[format] == "" {
+			return true
+		}
+		return present(f)
+	}
+	return true
+}
+
+// compareField checks v against other, the field at otherPath, by the tag of c.
+func compareField(v, other reflect.Value, c *comparison, otherPath string) *FieldError {
+	var code ErrorCode
+	var msg string
+	switch cmp := compareValues(v, other); c.tag {
+	case "eqfield":
+		if cmp == 0 {
+			return nil
+		}
+		code, msg = CodeNotEqualField, ERR_NOT_EQUAL_FIELD
+	case "nefield":
+		if cmp != 0 {
+			return nil
+		}
+		code, msg = CodeEqualField, ERR_EQUAL_FIELD
+	case "gtfield":
+		if cmp > 0 {
+			return nil
+		}
+		code, msg = CodeNotGreaterThanField, ERR_NOT_GREATER_THAN_FIELD
+	case "ltfield":
+		if cmp < 0 {
+			return nil
+		}
+		code, msg = CodeNotSmallerThanField, ERR_NOT_SMALLER_THAN_FIELD
+	}
+	return &FieldError{Tag: c.tag, Param: c.param, Value: v.Interface(), Code: code,
+		Message: fmt.Sprintf(msg, otherPath)}
+}
+
+// compareValues returns -1, 0 or 1 as a is smaller than, equal to or greater
+// than b, which have the same type. Booleans are only told equal or not.
+func compareValues(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return sign(a.Int() < b.Int(), a.Int() > b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return sign(a.Uint() < b.Uint(), a.Uint() > b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return sign(a.Float() < b.Float(), a.Float() > b.Float())
+	case reflect.Bool:
+		return sign(false, a.Bool() != b.Bool())
+	}
+	ta, tb := a.Interface().(time.Time), b.Interface().(time.Time)
+	return sign(ta.Before(tb), ta.After(tb))
+}
+
+func sign(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// indirect returns the value v points to, or v itself, and false for a nil pointer.
+func indirect(v reflect.Value) (reflect.Value, bool) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but reports false instead
+// of panicking on a nil pointer along the way, or as the field itself.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, i := range index {
+		var ok bool
+		if v, ok = indirect(v); !ok {
+			return v, false
+		}
+		v = v.Field(i)
+	}
+	return indirect(v)
+}
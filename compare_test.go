@@ -0,0 +1,136 @@
+package validator
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type priceRange struct {
+	Min float64 `form:"min" json:"min" valid:"required"`
+	Max float64 `form:"max" json:"max" valid:"required" gtfield:"Min"`
+}
+
+type signupParam struct {
+	Password        string     `form:"password" json:"password" valid:"required"`
+	PasswordConfirm string     `form:"password_confirm" json:"password_confirm" valid:"required" eqfield:"Password"`
+	Username        string     `form:"username" json:"username" valid:"required" nefield:"Password"`
+	Start           time.Time  `form:"start" json:"start" valid:"required" time_format:"2006-01-02"`
+	End             *time.Time `form:"end" json:"end" valid:"optional" time_format:"2006-01-02" gtfield:"Start"`
+	Price           priceRange `form:"price" json:"price" valid:"required"`
+	Budget          float64    `form:"budget" json:"budget" valid:"required" ltfield:"Price.Max"`
+}
+
+func signupBody() url.Values {
+	body := url.Values{}
+	body.Add("password", "s3cret")
+	body.Add("password_confirm", "s3cret")
+	body.Add("username", "tony")
+	body.Add("start", "2020-01-01")
+	body.Add("end", "2020-02-01")
+	body.Add("price.min", "10")
+	body.Add("price.max", "20")
+	body.Add("budget", "15")
+	return body
+}
+
+func TestCompareFields(t *testing.T) {
+	obj := signupParam{}
+	req := request("POST", "/", signupBody().Encode(), ContentTypeForm)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, 20.0, obj.Price.Max)
+}
+
+func TestCompareFieldsFailed(t *testing.T) {
+	body := signupBody()
+	body.Set("password_confirm", "secret")
+	body.Set("username", "s3cret")
+	body.Set("end", "2019-12-31")
+	body.Set("price.max", "10")
+	body.Set("budget", "25")
+
+	obj := signupParam{}
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "price.max: not greater than price.min; "+
+		"password_confirm: not equal to password; "+
+		"username: equal to password; "+
+		"end: not greater than start", err.Error()) // budget is not compared to the invalid price.max
+	assert.True(t, errors.Is(err, CodeNotEqualField))
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "price.max", fieldErr.Field)
+	assert.Equal(t, "gtfield", fieldErr.Tag)
+	assert.Equal(t, "Min", fieldErr.Param)
+	assert.Equal(t, CodeNotGreaterThanField, fieldErr.Code)
+}
+
+func TestCompareFieldsSkipped(t *testing.T) {
+	body := signupBody()
+	body.Del("end")
+	body.Set("password", "")
+	body.Set("password_confirm", "secret")
+
+	obj := signupParam{}
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	// Nothing is compared to an invalid field, nor an absent pointer
+	assert.Equal(t, "password: blank string", err.Error())
+}
+
+func TestCompareFieldsJson(t *testing.T) {
+	obj := signupParam{}
+	body := `{"password": "s3cret", "password_confirm": "secret", "username": "tony",
+		"start": "2020-01-01", "price": {"min": 10, "max": 20}, "budget": 25}`
+	req := request("POST", "/", body, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "password_confirm: not equal to password; budget: not smaller than price.max", err.Error())
+}
+
+type malformedCompareParam struct {
+	Name  string  `form:"name" valid:"required" eqfield:"Nickname"`
+	Age   int     `form:"age" valid:"required" gtfield:"Name"`
+	Admin bool    `form:"admin" valid:"required" gtfield:"Guest"`
+	Guest bool    `form:"guest" valid:"required"`
+	Price float64 `form:"price" valid:"required" ltfield:"Name.Len"`
+}
+
+func TestCompareMalformedTags(t *testing.T) {
+	err := Compile(&malformedCompareParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "Name: invalid `eqfield` tag, no field Nickname; "+
+		"Age: invalid `gtfield` tag, int can not be compared to string; "+
+		"Admin: invalid `gtfield` tag, bool can not be compared to bool; "+
+		"Price: invalid `ltfield` tag, no field Name.Len", err.Error())
+}
+
+type optionalRange struct {
+	Min int `form:"min" json:"min" valid:"optional"`
+	Max int `form:"max" json:"max" valid:"optional" gtfield:"Min"`
+}
+
+func TestCompareFieldsAbsent(t *testing.T) {
+	obj := optionalRange{}
+	req := request("GET", "/?min=5", "", "")
+	assert.NoError(t, Bind(req, &obj))
+
+	obj = optionalRange{}
+	req = request("GET", "/?max=5", "", "")
+	assert.NoError(t, Bind(req, &obj))
+
+	obj = optionalRange{}
+	req = request("POST", "/", `{"min": 5}`, ContentTypeJson)
+	assert.NoError(t, Bind(req, &obj))
+
+	req = request("POST", "/", `{"min": 5, "max": 5}`, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "max: not greater than min", err.Error())
+}
@@ -0,0 +1,125 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// condition is a conditional `valid` tag, e.g. `required_if=type:company`,
+// making a field required depending on a sibling field.
+type condition struct {
+	// kind is one of required_if, required_unless, required_with and required_without.
+	kind string
+	// param is the whole `valid` tag.
+	param string
+	// field is the sibling as written in the tag, either its Go field name or
+	// its name in a format.
+	field  string
+	values []string
+	// other is the sibling, resolved once the struct is parsed.
+	other *fieldSchema
+}
+
+// parseCondition parses the `valid` tag valid of kind, with the param after `=`.
+func parseCondition(valid, kind, param string) (*condition, bool) {
+	c := &condition{kind: kind, param: valid, field: param}
+	switch kind {
+	case "required_if", "required_unless":
+		i := strings.Index(param, ":")
+		if i <= 0 || i == len(param)-1 {
+			return nil, false
+		}
+		c.field, c.values = param[:i], strings.Split(param[i+1:], "|")
+	case "required_with", "required_without":
+		if param == "" {
+			return nil, false
+		}
+	}
+	return c, true
+}
+
+// resolveConditions looks up the sibling of every conditional field of s,
+// a schema of the struct type t.
+func resolveConditions(t reflect.Type, s *structSchema) {
+	for _, f := range s.fields {
+		c := f.rules.condition
+		if c == nil {
+			continue
+		}
+		for _, other := range s.fields {
+			if other != f && other.is(t, c.field) {
+				c.other = other
+				break
+			}
+		}
+		if c.other == nil {
+			f.rules.fail(&FieldError{Tag: "valid", Param: c.param, Code: CodeInvalidTag,
+				Message: fmt.Sprintf(ERR_INVALID_FIELD_TAG, "valid", c.field)})
+		}
+	}
+}
+
+// is reports whether f, a field of the struct type t, is called name, by
+// its Go field name or its name in a format.
+func (f *fieldSchema) is(t reflect.Type, name string) bool {
+	if t.Field(f.index).Name == name {
+		return true
+	}
+	for _, n := range f.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateConditions reports the conditional fields of the struct val at path
+// which are required but absent. present tells whether a field was sent.
+func validateConditions(val reflect.Value, s *structSchema, path string, format string,
+	present func(f *fieldSchema) bool, errs *Errors) {
+	for _, f := range s.fields {
+		c := f.rules.condition
+		if c == nil || c.other == nil {
+			continue
+		}
+		if _, ok := f.name(format); !ok {
+			continue
+		}
+		fieldPath := f.path(path, format)
+		if present(f) || errs.has(fieldPath) {
+			continue
+		}
+
+		otherPath := c.other.path(path, format)
+		var required bool
+		var msg string
+		switch c.kind {
+		case "required_if":
+			required = isIn(conditionValue(val, c.other), c.values)
+			msg = fmt.Sprintf(ERR_REQUIRED_IF, otherPath, strings.Join(c.values, "|"))
+		case "required_unless":
+			required = !isIn(conditionValue(val, c.other), c.values)
+			msg = fmt.Sprintf(ERR_REQUIRED_UNLESS, otherPath, strings.Join(c.values, "|"))
+		case "required_with":
+			required = present(c.other)
+			msg = fmt.Sprintf(ERR_REQUIRED_WITH, otherPath)
+		case "required_without":
+			required = !present(c.other)
+			msg = fmt.Sprintf(ERR_REQUIRED_WITHOUT, otherPath)
+		}
+		if required {
+			errs.add(fieldPath, &FieldError{Tag: "valid", Param: c.param, Code: CodeParamNotFound, Message: msg})
+		}
+	}
+}
+
+// conditionValue returns the coerced value of the field f of the struct val
+// as a string, or "" for a nil pointer.
+func conditionValue(val reflect.Value, f *fieldSchema) string {
+	v, ok := indirect(val.Field(f.index))
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,125 @@
+package validator
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type accountParam struct {
+	Type    string   `form:"type" json:"type" valid:"required" values:"person|company|ngo"`
+	Company string   `form:"company" json:"company" valid:"required_if=type:company|ngo"`
+	Name    string   `form:"name" json:"name" valid:"required_unless=Type:company"`
+	Email   string   `form:"email" json:"email" valid:"required_without=phone"`
+	Phone   string   `form:"phone" json:"phone" valid:"optional"`
+	Country *string  `form:"country" json:"country" valid:"required_with=Address"`
+	Address *address `form:"address" json:"address" valid:"optional"`
+}
+
+type address struct {
+	City string `form:"city" json:"city" valid:"required"`
+}
+
+func TestConditionalRequired(t *testing.T) {
+	body := url.Values{}
+	body.Add("type", "company")
+	body.Add("company", "Acme")
+	body.Add("phone", "+33123456789")
+	body.Add("country", "fr")
+	body.Add("address[city]", "Paris")
+
+	obj := accountParam{}
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "Acme", obj.Company)
+	assert.Equal(t, "Paris", obj.Address.City)
+
+	body = url.Values{}
+	body.Add("type", "person")
+	body.Add("name", "Tony")
+	body.Add("email", "tony@example.com")
+
+	obj = accountParam{}
+	req = request("POST", "/", body.Encode(), ContentTypeForm)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Nil(t, obj.Country)
+}
+
+func TestConditionalRequiredFailed(t *testing.T) {
+	body := url.Values{}
+	body.Add("type", "ngo")
+	body.Add("address.city", "Paris")
+
+	obj := accountParam{}
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "company: not found, required when type is company|ngo; "+
+		"name: not found, required unless type is company; "+
+		"email: not found, required without phone; "+
+		"country: not found, required with address", err.Error())
+	assert.True(t, errors.Is(err, CodeParamNotFound))
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "valid", fieldErr.Tag)
+	assert.Equal(t, "required_if=type:company|ngo", fieldErr.Param)
+}
+
+type accountJsonParam struct {
+	Type    string  `json:"type" valid:"required"`
+	Company *string `json:"company" valid:"required_if=type:company"`
+	Email   *string `json:"email" valid:"required_without=Phone"`
+	Phone   *string `json:"phone" valid:"optional"`
+}
+
+func TestConditionalRequiredJson(t *testing.T) {
+	obj := accountJsonParam{}
+	req := request("POST", "/", `{"type": "company", "phone": "+33123456789"}`, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "company: not found, required when type is company", err.Error())
+
+	obj = accountJsonParam{}
+	req = request("POST", "/", `{"type": "company", "company": "Acme"}`, ContentTypeJson)
+	err = Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "email: not found, required without phone", err.Error())
+}
+
+type accountUntaggedParam struct {
+	Type    string `valid:"required"`
+	Company string `valid:"required_if=Type:company"`
+	Min     int    `valid:"optional"`
+	Max     int    `valid:"optional" gtfield:"Min"`
+}
+
+func TestConditionalRequiredUntagged(t *testing.T) {
+	obj := accountUntaggedParam{}
+	req := request("POST", "/", `{"Type": "company", "Min": 2, "Max": 1}`, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "Company: not found, required when Type is company; Max: not greater than Min", err.Error())
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Company", fieldErr.Field)
+}
+
+type malformedConditionParam struct {
+	Company string `form:"company" valid:"required_if=type"`
+	Name    string `form:"name" valid:"required_with=nickname"`
+	Phone   string `form:"phone" valid:"required=true"`
+}
+
+func TestConditionalMalformedTags(t *testing.T) {
+	err := Compile(&malformedConditionParam{})
+	assert.Error(t, err)
+	errs := err.(Errors)
+	assert.Len(t, errs, 3)
+	assert.Equal(t, "Company", errs[0].Field)
+	assert.Equal(t, "Name: invalid `valid` tag, no field nickname", errs[1].Error())
+	assert.Equal(t, "Phone", errs[2].Field)
+}
@@ -1,10 +1,28 @@
 package validator
 
+import "errors"
+
 // Content Type
 const (
 	ContentTypeForm      = "application/x-www-form-urlencoded"
 	ContentTypeMultipart = "multipart/form-data"
 	ContentTypeJson      = "application/json"
+	ContentTypeXml       = "application/xml"
+	ContentTypeTextXml   = "text/xml"
+	ContentTypeYaml      = "application/yaml"
+	ContentTypeToml      = "application/toml"
+)
+
+// Request error, usable as sentinel error with errors.Is
+var (
+	ErrUnsupportedContentType = errors.New(ERR_UNSUPPORTED_CONTENT_TYPE)
+	ErrParseForm              = errors.New(ERR_PARSE_FORM)
+	ErrParseMultipartForm     = errors.New(ERR_PARSE_MULTIPART_FORM)
+	ErrDecodeJson             = errors.New(ERR_DECODE_JSON)
+	ErrDecodeXml              = errors.New(ERR_DECODE_XML)
+	ErrDecodeYaml             = errors.New(ERR_DECODE_YAML)
+	ErrDecodeToml             = errors.New(ERR_DECODE_TOML)
+	ErrInvalidTag             = errors.New(ERR_INVALID_TAG)
 )
 
 // Error Message
@@ -14,12 +32,19 @@ const (
 	ERR_UNSUPPORTED_CONTENT_TYPE = "unsupported Content-Type"
 	ERR_PARSE_FORM               = "parse form failed"
 	ERR_PARSE_MULTIPART_FORM     = "parse multipart form failed"
+	ERR_COMPILE_NOT_STRUCT       = "compile %T failed, pointer to struct expected"
 	ERR_DECODE_JSON              = "decode json failed"
+	ERR_DECODE_XML               = "decode xml failed"
+	ERR_DECODE_YAML              = "decode yaml failed"
+	ERR_DECODE_TOML              = "decode toml failed"
+	ERR_INVALID_TAG              = "invalid tag"
 
 	// Coerce error
 	ERR_OPTIONAL_PARAM_NOT_FOUND = "optional param not found"
 	ERR_PARAM_NOT_FOUND          = "not found"
 	ERR_PARAM_INVALID            = "%s expected"
+	ERR_PARAM_OVERFLOW           = "%s overflows %s"
+	ERR_INVALID_TIME             = "time expected in format `%s`"
 	ERR_CORRUPTED_FILE           = "corrupted file"
 	ERR_PARAM_FILE_NOT_FOUND     = "file not found"
 	ERR_FILE_TYPE_INVALID        = "file expected"
@@ -27,16 +52,111 @@ const (
 	// Validate error
 	ERR_PARAM_FILE_TOO_LARGE = "file larger than %d bytes"
 	ERR_INVALID_MAX_SIZE_TAG = "invalid `max_size` tag, must be int"
-	ERR_INVALID_VALID_TAG    = "invalid `valid` tag, must be `required` or `optional`"
-	ERR_INVALID_MAX_TAG      = "invalid `max` tag, must be int or float"
-	ERR_INVALID_MIN_TAG      = "invalid `min` tag, must be int or float"
-	ERR_INVALID_RANGE_TAG    = "invalid `range` tag, must be (int|int) or (float|float)"
-	ERR_INVALID_BASE64       = "invalid base64 string"
-	ERR_INVALID_UTF8_STRING  = "invalid utf8 string"
-	ERR_GREATER_THAN_MAX     = "greater than %s"
-	ERR_SMALLER_THAN_MIN     = "smaller than %s"
-	ERR_BLANK_STRING         = "blank string"
-	ERR_INVALID_ENUMERATION  = "%s is not in %s"
-	ERR_WRONG_FORMAT         = "wrong format, shold match regexp `%s`"
-	ERR_NOT_IN_RANGE         = "not in range (%s, %s)"
+	ERR_INVALID_VALID_TAG    = "invalid `valid` tag, must be `required`, `optional`, `required_if=field:value`, " +
+		"`required_unless=field:value`, `required_with=field` or `required_without=field`"
+	ERR_INVALID_MAX_TAG            = "invalid `max` tag, must be int or float"
+	ERR_INVALID_MIN_TAG            = "invalid `min` tag, must be int or float"
+	ERR_INVALID_RANGE_TAG          = "invalid `range` tag, must be (int|int) or (float|float)"
+	ERR_INVALID_TYPE_TAG           = "invalid `type` tag, must be `file` or `base64`"
+	ERR_TYPE_WITHOUT_BYTES         = "invalid `type` tag, only allowed on []byte"
+	ERR_MAX_SIZE_WITHOUT_FILE      = "invalid `max_size` tag, only allowed with `type:\"file\"`"
+	ERR_DEFAULT_WITHOUT_OPTIONAL   = "invalid `default` tag, only allowed with `valid:\"optional\"`"
+	ERR_INVALID_DEFAULT_TAG        = "invalid `default` tag, %s"
+	ERR_TAG_NOT_APPLICABLE         = "invalid `%s` tag, not allowed on %s"
+	ERR_INVALID_FIELD_TAG          = "invalid `%s` tag, no field %s"
+	ERR_INVALID_FIELD_TAG_TYPE     = "invalid `%s` tag, %s can not be compared to %s"
+	ERR_INVALID_MIN_LEN_TAG        = "invalid `min_len` tag, must be int"
+	ERR_INVALID_MAX_LEN_TAG        = "invalid `max_len` tag, must be int"
+	ERR_INVALID_LEN_TAG            = "invalid `len` tag, must be int"
+	ERR_INVALID_LEN_UNIT_TAG       = "invalid `len_unit` tag, must be `runes`, `bytes` or `graphemes`"
+	ERR_INVALID_MIN_ITEMS_TAG      = "invalid `min_items` tag, must be int"
+	ERR_INVALID_UNIQUE_TAG         = "invalid `unique` tag, must be bool"
+	ERR_INVALID_DIVE_TAG           = "invalid `dive` tag, must be a list of element tags such as `min_len=3,max_len=10`"
+	ERR_INVALID_FORMAT_TAG         = "invalid `format` tag, unknown format %s"
+	ERR_INVALID_ALLOW_EMPTY_TAG    = "invalid `allow_empty` tag, must be bool"
+	ERR_INVALID_NONBLANK_TAG       = "invalid `nonblank` tag, must be `true` or `trim`"
+	ERR_NONBLANK_WITH_ALLOW_EMPTY  = "invalid `nonblank` tag, not allowed with `allow_empty`"
+	ERR_INVALID_TRANSFORM_TAG      = "invalid `transform` tag, unknown transform %s"
+	ERR_INVALID_VALUES_TAG         = "invalid `%s` tag, %s"
+	ERR_INVALID_MAX_ITEMS_TAG      = "invalid `max_items` tag, must be int"
+	ERR_INVALID_REGEXP_TAG         = "invalid `regexp` tag, must be a valid regular expression"
+	ERR_INVALID_TIME_MAX_TAG       = "invalid `max` tag, must be `now[+-duration]` or time in `time_format`"
+	ERR_INVALID_TIME_MIN_TAG       = "invalid `min` tag, must be `now[+-duration]` or time in `time_format`"
+	ERR_INVALID_TIME_RANGE_TAG     = "invalid `range` tag, must be (time|time) in `time_format` or `now[+-duration]`"
+	ERR_INVALID_DURATION_MAX_TAG   = "invalid `max` tag, must be duration"
+	ERR_INVALID_DURATION_MIN_TAG   = "invalid `min` tag, must be duration"
+	ERR_INVALID_DURATION_RANGE_TAG = "invalid `range` tag, must be (duration|duration)"
+	ERR_INVALID_BASE64             = "invalid base64 string"
+	ERR_INVALID_UTF8_STRING        = "invalid utf8 string"
+	ERR_GREATER_THAN_MAX           = "greater than %s"
+	ERR_SMALLER_THAN_MIN           = "smaller than %s"
+	ERR_BLANK_STRING               = "blank string"
+	ERR_INVALID_ENUMERATION        = "%s is not in %s"
+	ERR_EXCLUDED_VALUE             = "%s is not allowed"
+	ERR_WRONG_FORMAT               = "wrong format, shold match regexp `%s`"
+	ERR_INVALID_FORMAT             = "not a valid %s"
+	ERR_NOT_IN_RANGE               = "not in range (%s, %s)"
+	ERR_LATER_THAN_MAX             = "later than %s"
+	ERR_EARLIER_THAN_MIN           = "earlier than %s"
+	ERR_TOO_SHORT                  = "shorter than %d %s"
+	ERR_TOO_LONG                   = "longer than %d %s"
+	ERR_WRONG_LENGTH               = "not %d %s long"
+	ERR_INVALID_MAP_KEY            = "key %s is not in %s"
+	ERR_REQUIRED_IF                = "not found, required when %s is %s"
+	ERR_REQUIRED_UNLESS            = "not found, required unless %s is %s"
+	ERR_REQUIRED_WITH              = "not found, required with %s"
+	ERR_REQUIRED_WITHOUT           = "not found, required without %s"
+	ERR_NOT_EQUAL_FIELD            = "not equal to %s"
+	ERR_EQUAL_FIELD                = "equal to %s"
+	ERR_NOT_GREATER_THAN_FIELD     = "not greater than %s"
+	ERR_NOT_SMALLER_THAN_FIELD     = "not smaller than %s"
+	ERR_TOO_FEW_ITEMS              = "fewer than %d items"
+	ERR_DUPLICATE_ITEM             = "duplicate item %v"
+	ERR_UNKNOWN_FIELD              = "unknown field"
+	ERR_UNKNOWN_NESTED_FIELD       = "unknown field %s"
+	ERR_DUPLICATE_KEY              = "duplicate key"
+	ERR_TRAILING_DATA              = "unexpected data after the body"
+	ERR_TOO_MANY_ITEMS             = "more than %d items"
+)
+
+// Error Code, usable as sentinel error with errors.Is
+const (
+	// Coerce error
+	CodeParamNotFound   ErrorCode = "not_found"
+	CodeParamInvalid    ErrorCode = "invalid_type"
+	CodeParamOverflow   ErrorCode = "overflow"
+	CodeCorruptedFile   ErrorCode = "corrupted_file"
+	CodeFileTypeInvalid ErrorCode = "file_expected"
+	CodeInvalidBase64   ErrorCode = "invalid_base64"
+
+	// codeOptionalParamNotFound marks an absent optional param, it is never reported
+	codeOptionalParamNotFound ErrorCode = "optional_not_found"
+
+	// Validate error
+	CodeInvalidTag          ErrorCode = "invalid_tag"
+	CodeFileTooLarge        ErrorCode = "file_too_large"
+	CodeInvalidUTF8String   ErrorCode = "invalid_utf8"
+	CodeBlankString         ErrorCode = "blank_string"
+	CodeInvalidEnumeration  ErrorCode = "not_in_values"
+	CodeExcludedValue       ErrorCode = "excluded_value"
+	CodeWrongFormat         ErrorCode = "wrong_format"
+	CodeInvalidFormat       ErrorCode = "invalid_format"
+	CodeGreaterThanMax      ErrorCode = "greater_than_max"
+	CodeSmallerThanMin      ErrorCode = "smaller_than_min"
+	CodeNotInRange          ErrorCode = "not_in_range"
+	CodeTooShort            ErrorCode = "too_short"
+	CodeTooLong             ErrorCode = "too_long"
+	CodeWrongLength         ErrorCode = "wrong_length"
+	CodeInvalidMapKey       ErrorCode = "invalid_key"
+	CodeTooManyItems        ErrorCode = "too_many_items"
+	CodeNotEqualField       ErrorCode = "not_equal_field"
+	CodeEqualField          ErrorCode = "equal_field"
+	CodeNotGreaterThanField ErrorCode = "not_greater_than_field"
+	CodeNotSmallerThanField ErrorCode = "not_smaller_than_field"
+	CodeTooFewItems         ErrorCode = "too_few_items"
+	CodeDuplicateItem       ErrorCode = "duplicate_item"
+	CodeUnknownField        ErrorCode = "unknown_field"
+	CodeDuplicateKey        ErrorCode = "duplicate_key"
+	CodeTrailingData        ErrorCode = "trailing_data"
+	CodeInvalidStruct       ErrorCode = "invalid_struct"
 )
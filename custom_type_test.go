@@ -0,0 +1,145 @@
+package validator
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type color int
+
+const (
+	red color = iota + 1
+	green
+)
+
+func (c *color) Coerce(param string) error {
+	switch param {
+	case "red":
+		*c = red
+	case "green":
+		*c = green
+	default:
+		return fmt.Errorf("unknown color %q", param)
+	}
+	return nil
+}
+
+type cents struct {
+	Amount int64
+}
+
+func init() {
+	RegisterTypeCoercer(reflect.TypeOf(cents{}), func(s string) (interface{}, error) {
+		parts := strings.SplitN(s, ".", 2)
+		units, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil || len(parts) == 2 && len(parts[1]) != 2 {
+			return nil, fmt.Errorf("invalid amount %q", s)
+		}
+		amount := units * 100
+		if len(parts) == 2 {
+			c, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			amount += c
+		}
+		return cents{Amount: amount}, nil
+	})
+}
+
+type customTypeParam struct {
+	IP     net.IP   `form:"ip" json:"ip" valid:"required"`
+	Hosts  []net.IP `form:"hosts" json:"hosts" valid:"optional"`
+	Color  color    `form:"color" json:"color" valid:"required"`
+	Colors []color  `form:"colors" json:"colors" valid:"optional"`
+	Price  cents    `form:"price" json:"price" valid:"required"`
+	Refund *cents   `form:"refund" json:"refund" valid:"optional"`
+}
+
+func TestCustomTypeForm(t *testing.T) {
+	obj := customTypeParam{}
+
+	body := url.Values{}
+	body.Add("ip", "10.0.0.1")
+	body.Add("hosts[]", "::1")
+	body.Add("hosts[]", "192.168.0.1")
+	body.Add("color", "green")
+	body.Add("colors[]", "red")
+	body.Add("price", "12.34")
+	req := request("GET", "/?"+body.Encode(), "", "")
+	err := Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", obj.IP.String())
+	assert.Equal(t, "::1", obj.Hosts[0].String())
+	assert.Equal(t, "192.168.0.1", obj.Hosts[1].String())
+	assert.Equal(t, green, obj.Color)
+	assert.Equal(t, []color{red}, obj.Colors)
+	assert.Equal(t, cents{Amount: 1234}, obj.Price)
+	assert.Nil(t, obj.Refund)
+}
+
+func TestCustomTypeFormErrors(t *testing.T) {
+	obj := customTypeParam{}
+
+	body := url.Values{}
+	body.Add("ip", "10.0.0")
+	body.Add("color", "blue")
+	body.Add("price", "12.3")
+	body.Add("refund", "x")
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "ip: net.IP expected; color: validator.color expected; price: validator.cents expected; "+
+		"refund: validator.cents expected", err.Error())
+}
+
+func TestCustomTypeJson(t *testing.T) {
+	obj := customTypeParam{}
+
+	body := `{"ip": "10.0.0.1", "color": "red", "price": "1.50", "refund": "0.50"}`
+	req := request("POST", "/", body, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", obj.IP.String())
+	assert.Equal(t, red, obj.Color)
+	assert.Equal(t, cents{Amount: 150}, obj.Price)
+	assert.Equal(t, &cents{Amount: 50}, obj.Refund)
+}
+
+func TestCustomTypeJsonItems(t *testing.T) {
+	type param struct {
+		Hosts   []net.IP         `json:"hosts" valid:"optional"`
+		Colors  []color          `json:"colors" valid:"optional"`
+		Prices  []cents          `json:"prices" valid:"optional"`
+		Refunds *[]*cents        `json:"refunds" valid:"optional"`
+		Fees    map[string]cents `json:"fees" valid:"optional"`
+	}
+	obj := param{}
+	body := `{"hosts": ["::1"], "colors": ["red", "green"], "prices": ["1.50", "2"], "refunds": ["0.50"],
+		"fees": {"card": "0.25"}}`
+	req := request("POST", "/", body, ContentTypeJson)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "::1", obj.Hosts[0].String())
+	assert.Equal(t, []color{red, green}, obj.Colors)
+	assert.Equal(t, []cents{{Amount: 150}, {Amount: 200}}, obj.Prices)
+	assert.Equal(t, &cents{Amount: 50}, (*obj.Refunds)[0])
+	assert.Equal(t, cents{Amount: 25}, obj.Fees["card"])
+
+	// Numbers are decoded by encoding/json, as for a single value
+	obj = param{}
+	req = request("POST", "/", `{"colors": [1]}`, ContentTypeJson)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, []color{1}, obj.Colors)
+
+	req = request("POST", "/", `{"prices": ["1.5"], "fees": {"card": "x"}}`, ContentTypeJson)
+	err := Bind(req, &param{})
+	assert.Error(t, err)
+	assert.Equal(t, "prices: validator.cents expected; fees.card: validator.cents expected", err.Error())
+}
@@ -0,0 +1,401 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// document is a value of a yaml or toml body, either a scalar, a sequence or
+// a mapping, decoded as is before it is bound to a struct field by field.
+type document struct {
+	// line is the line of the key of the value, or of the item of a sequence,
+	// and 0 for the root.
+	line   int
+	scalar *string
+	// resolved is the value of a yaml number or boolean as resolved by its
+	// tag, e.g. 31 for 0x1F, while scalar keeps its text.
+	resolved *string
+	items    []*document
+	fields   map[string]*document
+	// budget bounds the binding of a yaml body, whose aliases share the
+	// values of their anchor. It is nil for toml.
+	budget *aliasBudget
+}
+
+// errExcessiveAliasing is returned when binding a yaml body would expand its
+// aliases beyond the budget.
+var errExcessiveAliasing = errors.New("document contains excessive aliasing")
+
+// aliasBudget counts the values bound from a yaml body against its nodes, so
+// that aliases expanded again and again, as in a "billion laughs" body, are
+// rejected before they exhaust memory. The allowed ratio of expanded values
+// is the one of the yaml package.
+type aliasBudget struct {
+	nodes int
+	bound int
+}
+
+// spend charges the budget of d with the values held by d, when it is bound.
+func (d *document) spend() error {
+	if d == nil || d.budget == nil {
+		return nil
+	}
+	b := d.budget
+	b.bound += len(d.fields) + len(d.items)
+	aliased := b.bound - b.nodes
+	if aliased > 100 && b.bound > 1000 && float64(aliased)/float64(b.bound) > allowedAliasRatio(b.bound) {
+		return errExcessiveAliasing
+	}
+	return nil
+}
+
+// allowedAliasRatio is the ratio of values bound through aliases allowed for
+// bound values in all, as in the yaml package: 99% for small bodies, down to
+// 10% for large ones.
+func allowedAliasRatio(bound int) float64 {
+	const low, high = 400000, 4000000
+	switch {
+	case bound <= low:
+		return 0.99
+	case bound >= high:
+		return 0.10
+	}
+	return 0.99 - 0.89*float64(bound-low)/float64(high-low)
+}
+
+// docError returns the error for a value at path which is not of the kind want.
+func docError(path string, want string, d *document) error {
+	if path == "" {
+		return fmt.Errorf("%s expected, found %s", want, d.kind())
+	}
+	return fmt.Errorf("%s: %s expected, found %s", path, want, d.kind())
+}
+
+func (d *document) kind() string {
+	switch {
+	case d.scalar != nil:
+		return "scalar"
+	case d.fields != nil:
+		return "mapping"
+	}
+	return "sequence"
+}
+
+// params returns the value of a scalar, or the values of a sequence of
+// scalars, as form params for a field of type t. It reports false for other
+// documents.
+func (d *document) params(t reflect.Type) ([]string, bool) {
+	if d.scalar != nil {
+		return []string{d.param(t)}, true
+	}
+	if d.fields != nil {
+		return nil, false
+	}
+	params := make([]string, 0, len(d.items))
+	for _, item := range d.items {
+		if item == nil || item.scalar == nil {
+			return nil, false
+		}
+		params = append(params, item.param(t))
+	}
+	return params, true
+}
+
+// param returns the scalar d for a field of type t: its resolved value for a
+// number or boolean field, and its text otherwise, as the yaml package does.
+func (d *document) param(t reflect.Type) string {
+	t = elemType(t)
+	if d.resolved == nil || isCoercible(t) {
+		return *d.scalar
+	}
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return *d.resolved
+	}
+	return *d.scalar
+}
+
+// lookup returns the value of the key name of a mapping, preferring an exact
+// match but accepting a case-insensitive one, as lookupJson does. Absent and
+// null values are both returned as nil.
+func (d *document) lookup(name string) *document {
+	if v, ok := d.fields[name]; ok {
+		return v
+	}
+	for key, v := range d.fields {
+		if strings.EqualFold(key, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+// decodeDocument binds the mapping doc to the struct val field by field, with
+// the semantics of decodeJson for the tags of format, `yaml` or `toml`:
+// scalars are coerced as form params are, an absent or null key is reported
+// for a required field and set to the `default` of an optional one, and
+// nested mappings are bound to nested structs. Invalid fields are reported in
+// errs with the line of their key, while a malformed body is returned as
+// error.
+func decodeDocument(doc *document, val reflect.Value, path string, format string, errs *Errors) error {
+	if doc.fields == nil {
+		return docError(path, "mapping", doc)
+	}
+
+	start := len(*errs)
+	lines := make(map[string]int, len(doc.fields))
+	s := schemaOf(val.Type())
+	present := make(map[*fieldSchema]bool, len(s.fields))
+	for _, f := range s.fields {
+		field := val.Field(f.index)
+		sf := val.Type().Field(f.index)
+		name, ok := f.name(format)
+		if !ok {
+			continue
+		}
+		// Fields of an embedded struct are promoted
+		if f.embedded && name == "" {
+			if field.Kind() == reflect.Ptr {
+				if field.IsNil() {
+					field.Set(reflect.New(field.Type().Elem()))
+				}
+				field = field.Elem()
+			}
+			if err := decodeDocument(doc, field, path, format, errs); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fieldPath := joinPath(path, name)
+
+		v := doc.lookup(name)
+		if err := v.spend(); err != nil {
+			return err
+		}
+		present[f] = v != nil
+		if v != nil {
+			lines[fieldPath] = v.line
+		}
+
+		n := len(*errs)
+		switch {
+		case f.kind == fieldNested:
+			if v == nil {
+				if err := decodeAbsentDocument(field, f.rules, fieldPath, format, errs); err != nil {
+					return err
+				}
+				continue
+			}
+			elem := field
+			if elem.Kind() == reflect.Ptr {
+				if elem.IsNil() {
+					elem.Set(reflect.New(elem.Type().Elem()))
+				}
+				elem = elem.Elem()
+			}
+			if err := decodeDocument(v, elem, fieldPath, format, errs); err != nil {
+				return err
+			}
+			if len(*errs) > n {
+				continue
+			}
+		case isStructMap(sf.Type):
+			if v == nil {
+				if f.rules.valid == "required" {
+					errs.add(fieldPath, &FieldError{Tag: "valid", Param: "required", Code: CodeParamNotFound,
+						Message: ERR_PARAM_NOT_FOUND})
+				}
+				continue
+			}
+			if err := decodeDocumentEntries(v, field, fieldPath, format, errs); err != nil {
+				return err
+			}
+			if len(*errs) == n {
+				validateMap(field, f.rules, fieldPath, errs)
+			}
+			if len(*errs) > n {
+				continue
+			}
+		case f.kind == fieldMap:
+			entries := make(map[string][]string)
+			if v != nil {
+				if v.fields == nil {
+					return docError(fieldPath, "mapping", v)
+				}
+				for key, entry := range v.fields {
+					if entry == nil {
+						continue
+					}
+					params, ok := entry.params(field.Type().Elem())
+					if !ok {
+						return docError(joinPath(fieldPath, key), "scalar", entry)
+					}
+					entries[joinPath(fieldPath, key)] = params
+					lines[joinPath(fieldPath, key)] = entry.line
+				}
+			}
+			coerceMap(field, formPath{dotted: fieldPath, bracketed: fieldPath}, f.rules, entries, errs)
+			if len(*errs) > n {
+				continue
+			}
+		case isNestedStruct(elemType(sf.Type)):
+			// Such as a slice of structs
+			if v == nil {
+				if err := coerceField(field, nil, f.rules, nil, nil); err != nil {
+					if err.Code != codeOptionalParamNotFound {
+						errs.add(fieldPath, err)
+					}
+					continue
+				}
+			} else if err := decodeDocumentItems(v, field, fieldPath, format, errs); err != nil {
+				return err
+			}
+			if err := validateField(field, f.rules); err != nil {
+				errs.add(fieldPath, err)
+				continue
+			}
+		default:
+			params := make(map[string][]string, 1)
+			if v != nil {
+				var ok bool
+				if params[name], ok = v.params(sf.Type); !ok {
+					return docError(fieldPath, "scalar", v)
+				}
+			}
+			err := coerceField(field, []string{name}, f.rules, params, nil)
+			if err == nil {
+				err = validateField(field, f.rules)
+			}
+			if err != nil {
+				if err.Code != codeOptionalParamNotFound {
+					errs.add(fieldPath, err)
+				}
+				continue
+			}
+		}
+		if err := validateRules(field, f, val, fieldPath); err != nil {
+			errs.add(fieldPath, err)
+		}
+	}
+
+	sent := func(f *fieldSchema) bool { return present[f] }
+	validateConditions(val, s, path, format, sent, errs)
+	validateComparisons(val, s, path, format, sent, errs)
+
+	// Nested structs have set the lines of their own errors
+	for _, e := range (*errs)[start:] {
+		if e.Line == 0 {
+			e.Line = lineOf(lines, e.Field, doc.line)
+		}
+	}
+	return nil
+}
+
+// lineOf returns the line of the key at path, or of its closest parent in
+// lines, and line if none is found.
+func lineOf(lines map[string]int, path string, line int) int {
+	for path != "" {
+		if l, ok := lines[path]; ok {
+			return l
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return line
+}
+
+// decodeAbsentDocument handles a struct or pointer-to-struct field whose key
+// is absent, as decodeAbsentJson does.
+func decodeAbsentDocument(val reflect.Value, r *rules, path string, format string, errs *Errors) error {
+	switch r.valid {
+	case "required":
+		errs.add(path, &FieldError{Tag: "valid", Param: "required", Code: CodeParamNotFound,
+			Message: ERR_PARAM_NOT_FOUND})
+		return nil
+	case "optional":
+		return nil
+	default:
+		// Absent pointers are left nil
+		if r.condition != nil || val.Kind() == reflect.Ptr {
+			return nil
+		}
+	}
+	return decodeDocument(&document{fields: map[string]*document{}}, val, path, format, errs)
+}
+
+// decodeDocumentItems binds the mappings of the sequence doc to val, a slice
+// of structs, item by item with decodeDocument, so that each struct is
+// validated at its index, e.g. `items.0.sku`.
+func decodeDocumentItems(doc *document, val reflect.Value, path string, format string, errs *Errors) error {
+	if doc.scalar != nil || doc.fields != nil {
+		return docError(path, "sequence", doc)
+	}
+	slice := reflect.MakeSlice(val.Type(), len(doc.items), len(doc.items))
+	for i, item := range doc.items {
+		if item == nil {
+			continue
+		}
+		if err := item.spend(); err != nil {
+			return err
+		}
+		elem := slice.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			elem.Set(reflect.New(elem.Type().Elem()))
+			elem = elem.Elem()
+		}
+		if err := decodeDocument(item, elem, joinPath(path, strconv.Itoa(i)), format, errs); err != nil {
+			return err
+		}
+	}
+	val.Set(slice)
+	return nil
+}
+
+// decodeDocumentEntries binds the values of the mapping doc to val, a map of
+// structs, value by value with decodeDocument, so that each struct is
+// validated at its key, e.g. `lines.a.sku`.
+func decodeDocumentEntries(doc *document, val reflect.Value, path string, format string, errs *Errors) error {
+	if doc.fields == nil {
+		return docError(path, "mapping", doc)
+	}
+	keys := make([]string, 0, len(doc.fields))
+	for key := range doc.fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	m := reflect.MakeMapWithSize(val.Type(), len(doc.fields))
+	for _, key := range keys {
+		entry := doc.fields[key]
+		elem := reflect.New(val.Type().Elem()).Elem()
+		if entry != nil {
+			if err := entry.spend(); err != nil {
+				return err
+			}
+			field := elem
+			if field.Kind() == reflect.Ptr {
+				field.Set(reflect.New(field.Type().Elem()))
+				field = field.Elem()
+			}
+			if err := decodeDocument(entry, field, joinPath(path, key), format, errs); err != nil {
+				return err
+			}
+		}
+		m.SetMapIndex(reflect.ValueOf(key).Convert(val.Type().Key()), elem)
+	}
+	val.Set(m)
+	return nil
+}
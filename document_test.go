@@ -0,0 +1,309 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type documentItem struct {
+	Sku string `yaml:"sku" toml:"sku"`
+	Qty int    `yaml:"qty" toml:"qty"`
+}
+
+type documentAddress struct {
+	City string `yaml:"city" toml:"city" valid:"required"`
+	Zip  string `yaml:"zip" toml:"zip" valid:"optional" default:"00000"`
+}
+
+type documentParam struct {
+	Name     string            `yaml:"name" toml:"name" valid:"required" min_len:"2"`
+	Port     int               `yaml:"port" toml:"port" valid:"required" range:"1|65535"`
+	Debug    *bool             `yaml:"debug" toml:"debug" valid:"optional"`
+	Greeting string            `yaml:"greeting" toml:"greeting" valid:"optional" default:"hello"`
+	Since    time.Time         `yaml:"since" toml:"since" valid:"optional" time_format:"2006-01-02"`
+	Tags     []string          `yaml:"tags" toml:"tags" valid:"optional" max_items:"2"`
+	Meta     map[string]string `yaml:"meta" toml:"meta" valid:"optional" keys:"color|size"`
+	Address  documentAddress   `yaml:"address" toml:"address"`
+	Items    []documentItem    `yaml:"items" toml:"items" valid:"optional" min_items:"1"`
+	Phone    string            `yaml:"phone" toml:"phone" valid:"required_with=email"`
+	Email    string            `yaml:"email" toml:"email" valid:"optional"`
+}
+
+func TestYaml(t *testing.T) {
+	body := `name: Tony
+port: 8080
+debug: false
+greeting: ~
+since: 2020-02-03
+tags: [go, yaml]
+meta:
+  color: red
+address:
+  city: Paris
+items:
+  - sku: a
+    qty: 2
+  - sku: b
+    qty: 3
+`
+	obj := documentParam{}
+	req := request("POST", "/", body, ContentTypeYaml)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "Tony", obj.Name)
+	assert.Equal(t, 8080, obj.Port)
+	assert.Equal(t, false, *obj.Debug)
+	assert.Equal(t, "hello", obj.Greeting)
+	assert.Equal(t, time.Date(2020, 2, 3, 0, 0, 0, 0, time.UTC), obj.Since)
+	assert.Equal(t, []string{"go", "yaml"}, obj.Tags)
+	assert.Equal(t, map[string]string{"color": "red"}, obj.Meta)
+	assert.Equal(t, documentAddress{City: "Paris", Zip: "00000"}, obj.Address)
+	assert.Equal(t, []documentItem{{"a", 2}, {"b", 3}}, obj.Items)
+}
+
+func TestYamlErrors(t *testing.T) {
+	body := `name: T
+port: 70000
+tags:
+  - a
+  - b
+  - c
+meta:
+  weight: 1
+address:
+  zip: "1"
+email: a@b.c
+`
+	req := request("POST", "/", body, ContentTypeYaml)
+	err := Bind(req, &documentParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "name: shorter than 2 characters (line 1); port: not in range (1, 65535) (line 2); "+
+		"tags: more than 2 items (line 3); meta.weight: key weight is not in [color size] (line 8); "+
+		"address.city: not found (line 9); phone: not found, required with email", err.Error())
+	errs := err.(Errors)
+	assert.Equal(t, 2, errs[1].Line)
+	assert.Equal(t, 0, errs[5].Line)
+	assert.True(t, errors.Is(err, CodeParamNotFound))
+
+	req = request("POST", "/", "name: {a: b}\n", ContentTypeYaml)
+	err = Bind(req, &documentParam{})
+	assert.True(t, errors.Is(err, ErrDecodeYaml))
+
+	req = request("POST", "/", "name: [a\n", ContentTypeYaml)
+	err = Bind(req, &documentParam{})
+	assert.True(t, errors.Is(err, ErrDecodeYaml))
+}
+
+func TestToml(t *testing.T) {
+	body := `name = "Tony"
+port = 8080
+debug = false
+since = 2020-02-03
+tags = ["go", "toml"]
+meta = { color = "red" }
+
+[address]
+city = "Paris"
+
+[[items]]
+sku = "a"
+qty = 2
+
+[[items]]
+sku = "b"
+qty = 3
+`
+	obj := documentParam{}
+	req := request("POST", "/", body, ContentTypeToml)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "Tony", obj.Name)
+	assert.Equal(t, 8080, obj.Port)
+	assert.Equal(t, false, *obj.Debug)
+	assert.Equal(t, "hello", obj.Greeting)
+	assert.Equal(t, time.Date(2020, 2, 3, 0, 0, 0, 0, time.UTC), obj.Since)
+	assert.Equal(t, []string{"go", "toml"}, obj.Tags)
+	assert.Equal(t, map[string]string{"color": "red"}, obj.Meta)
+	assert.Equal(t, documentAddress{City: "Paris", Zip: "00000"}, obj.Address)
+	assert.Equal(t, []documentItem{{"a", 2}, {"b", 3}}, obj.Items)
+}
+
+func TestTomlErrors(t *testing.T) {
+	body := `name = "T"
+port = 70000
+tags = ["a", "b", "c"]
+email = "a@b.c"
+
+[meta]
+weight = "1"
+
+[address]
+zip = "1"
+`
+	req := request("POST", "/", body, ContentTypeToml)
+	err := Bind(req, &documentParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "name: shorter than 2 characters (line 1); port: not in range (1, 65535) (line 2); "+
+		"tags: more than 2 items (line 3); meta.weight: key weight is not in [color size] (line 7); "+
+		"address.city: not found (line 9); phone: not found, required with email", err.Error())
+
+	req = request("POST", "/", "name = \n", ContentTypeToml)
+	err = Bind(req, &documentParam{})
+	assert.True(t, errors.Is(err, ErrDecodeToml))
+}
+
+func TestTomlLines(t *testing.T) {
+	lines := tomlLines([]byte(`a = 1
+b.c = { d = 2, e = [{ j = 1 }] }
+
+[[f]]
+g = 4
+
+[[f]]
+g = 5
+
+[f.h]
+i = 6
+`))
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "b.c": 2, "b.c.d": 2, "b.c.e": 2, "b.c.e.0": 2,
+		"b.c.e.0.j": 2, "f": 4, "f.0": 4, "f.0.g": 5, "f.1": 7, "f.1.g": 8, "f.1.h": 10, "f.1.h.i": 11}, lines)
+}
+
+func TestDocumentRecursive(t *testing.T) {
+	obj := nodeParam{}
+	req := request("POST", "/", "name: x\n", ContentTypeYaml)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "x", obj.Name)
+	assert.Nil(t, obj.Child)
+
+	obj = nodeParam{}
+	req = request("POST", "/", "name = \"x\"\n", ContentTypeToml)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "x", obj.Name)
+	assert.Nil(t, obj.Child)
+
+	obj = nodeParam{}
+	req = request("POST", "/", "name = \"x\"\n[child]\nname = \"y\"\n", ContentTypeToml)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "y", obj.Child.Name)
+	assert.Nil(t, obj.Child.Child)
+}
+
+func TestDocumentItemsStructs(t *testing.T) {
+	obj := orderParam{}
+	req := request("POST", "/", "items:\n  - sku: a\n  - sku: b\n    qty: 2\n", ContentTypeYaml)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, []orderLine{{"a", 1}, {"b", 2}}, obj.Items)
+
+	req = request("POST", "/", "items:\n  - qty: 0\n", ContentTypeYaml)
+	err := Bind(req, &orderParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "items.0.sku: not found (line 2); items.0.qty: smaller than 1 (line 2)", err.Error())
+
+	body := `[[items]]
+sku = "a"
+
+[[items]]
+qty = 0
+`
+	req = request("POST", "/", body, ContentTypeToml)
+	err = Bind(req, &orderParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "items.1.sku: not found (line 4); items.1.qty: smaller than 1 (line 5)", err.Error())
+}
+
+type yamlConfigParam struct {
+	Host    string  `yaml:"host" valid:"required"`
+	Port    int     `yaml:"port" valid:"required" range:"1|65535"`
+	Mask    uint8   `yaml:"mask" valid:"optional"`
+	Ratio   float64 `yaml:"ratio" valid:"optional"`
+	Code    string  `yaml:"code" valid:"optional"`
+	Retries []int   `yaml:"retries" valid:"optional"`
+	Debug   bool    `yaml:"debug" valid:"optional"`
+}
+
+type yamlConfigsParam struct {
+	Dev  yamlConfigParam `yaml:"dev"`
+	Prod yamlConfigParam `yaml:"prod"`
+}
+
+func TestYamlScalars(t *testing.T) {
+	obj := yamlConfigParam{}
+	body := "host: db\nport: 0x1F90\nmask: 0o17\nratio: 1e3\ncode: 0x1F\nretries: [0x1, 2]\ndebug: True\n"
+	req := request("POST", "/", body, ContentTypeYaml)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, 8080, obj.Port)
+	assert.Equal(t, uint8(15), obj.Mask)
+	assert.Equal(t, 1000.0, obj.Ratio)
+	assert.Equal(t, "0x1F", obj.Code)
+	assert.Equal(t, []int{1, 2}, obj.Retries)
+	assert.True(t, obj.Debug)
+}
+
+func TestYamlMergeKeys(t *testing.T) {
+	body := `base: &base
+  host: db
+  port: 5432
+  debug: true
+dev:
+  <<: *base
+  port: 5433
+prod:
+  <<: [*base]
+  host: prod
+`
+	obj := yamlConfigsParam{}
+	req := request("POST", "/", body, ContentTypeYaml)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, yamlConfigParam{Host: "db", Port: 5433, Debug: true}, obj.Dev)
+	assert.Equal(t, yamlConfigParam{Host: "prod", Port: 5432, Debug: true}, obj.Prod)
+}
+
+// laughs returns a "billion laughs" yaml body of 8 levels of 10 aliases of
+// "lol", or of mappings holding them under key if it is not empty.
+func laughs(key string) string {
+	value := func(s string) string {
+		if key == "" {
+			return s
+		}
+		return "{" + key + ": " + s + "}"
+	}
+	lol := `"lol"`
+	if key != "" {
+		lol = "{}"
+	}
+	body := "a0: &a0 " + value("["+strings.TrimSuffix(strings.Repeat(lol+",", 10), ",")+"]") + "\n"
+	for i := 1; i <= 8; i++ {
+		alias := fmt.Sprintf("*a%d", i-1)
+		body += fmt.Sprintf("a%d: &a%d ", i, i) + value("["+strings.TrimSuffix(strings.Repeat(alias+",", 10), ",")+"]") + "\n"
+	}
+	return body
+}
+
+type laughParam struct {
+	L  []laughParam `yaml:"l"`
+	A8 []laughParam `yaml:"a8"`
+}
+
+func TestYamlAliases(t *testing.T) {
+	// Aliases which are not bound are not expanded
+	obj := nodeParam{}
+	req := request("POST", "/", laughs("")+"name: x\n", ContentTypeYaml)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "x", obj.Name)
+
+	req = request("POST", "/", laughs("l")+"l: [*a8]\n", ContentTypeYaml)
+	err := Bind(req, &laughParam{})
+	assert.True(t, errors.Is(err, ErrDecodeYaml))
+	assert.Contains(t, err.Error(), "excessive aliasing")
+
+	body := "base: &base {l: [{}, {}]}\na8: [*base, *base]\n"
+	req = request("POST", "/", body, ContentTypeYaml)
+	obj2 := laughParam{}
+	assert.NoError(t, Bind(req, &obj2))
+	assert.Len(t, obj2.A8, 2)
+	assert.Len(t, obj2.A8[1].L, 2)
+}
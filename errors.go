@@ -0,0 +1,109 @@
+package validator
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrorCode is a stable machine-readable identifier of a failure. It
+// implements error, so the Code* constants can be matched with errors.Is:
+//
+//	if errors.Is(err, validator.CodeParamNotFound) {
+//		w.WriteHeader(http.StatusBadRequest)
+//	}
+type ErrorCode string
+
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
+// FieldError describes a single param that failed to bind or validate.
+type FieldError struct {
+	// Field is the name of the param as seen by the client, e.g. `age`.
+	Field string
+	// Tag is the struct tag that rejected the param, e.g. `max`.
+	Tag string
+	// Param is the value of that tag, e.g. `25`.
+	Param string
+	// Value is the offending value, either the raw string or the bound value.
+	Value interface{}
+	// Code is a stable machine-readable identifier of the failure.
+	Code ErrorCode
+	// Message is the human-readable reason, without the field name.
+	Message string
+	// Line is the line of the param in a yaml or toml body, or 0.
+	Line int
+}
+
+func (e *FieldError) Error() string {
+	msg := e.Message
+	if e.Field != "" {
+		msg = e.Field + ": " + msg
+	}
+	if e.Line > 0 {
+		msg += " (line " + strconv.Itoa(e.Line) + ")"
+	}
+	return msg
+}
+
+// Unwrap returns the Code of e, so errors.Is(e, CodeXxx) holds.
+func (e *FieldError) Unwrap() error {
+	return e.Code
+}
+
+// Errors collects every FieldError found while binding a request.
+type Errors []*FieldError
+
+func (errs Errors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any error in errs matches target.
+func (errs Errors) Is(target error) bool {
+	for _, e := range errs {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error in errs that matches target, and if so, sets
+// target to that error value and returns true.
+func (errs Errors) As(target interface{}) bool {
+	for _, e := range errs {
+		if errors.As(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// add appends err to errs, naming it after field.
+func (errs *Errors) add(field string, err *FieldError) {
+	err.Field = field
+	*errs = append(*errs, err)
+}
+
+// has reports whether a FieldError of errs is named after field.
+func (errs Errors) has(field string) bool {
+	for _, e := range errs {
+		if e.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// orNil returns errs as an error, or nil if it is empty.
+func (errs Errors) orNil() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
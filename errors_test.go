@@ -0,0 +1,74 @@
+package validator
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type multiErrorParam struct {
+	Name  string  `form:"name" json:"name" valid:"required"`
+	Age   int     `form:"age" json:"age" valid:"required" max:"25"`
+	Score float32 `form:"score" json:"score" valid:"required" min:"60.0"`
+}
+
+func TestErrorsCollectAllFields(t *testing.T) {
+	obj := multiErrorParam{}
+
+	body := url.Values{}
+	body.Add("age", "28")
+	body.Add("score", "abc")
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "name: not found; age: greater than 25; score: float32 expected", err.Error())
+
+	errs, ok := err.(Errors)
+	assert.True(t, ok)
+	assert.Len(t, errs, 3)
+	assert.Equal(t, &FieldError{Field: "age", Tag: "max", Param: "25", Value: 28,
+		Code: CodeGreaterThanMax, Message: "greater than 25"}, errs[1])
+	assert.Equal(t, "abc", errs[2].Value)
+	assert.Equal(t, CodeParamInvalid, errs[2].Code)
+}
+
+func TestErrorsJson(t *testing.T) {
+	obj := multiErrorParam{}
+
+	req := request("POST", "/", `{"name": "", "age": 30, "score": 10}`, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "name: blank string; age: greater than 25; score: smaller than 60.0", err.Error())
+}
+
+func TestErrorsIsAndAs(t *testing.T) {
+	obj := multiErrorParam{}
+
+	body := url.Values{}
+	body.Add("name", "Tony")
+	body.Add("age", "28")
+	body.Add("score", "80")
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.True(t, errors.Is(err, CodeGreaterThanMax))
+	assert.False(t, errors.Is(err, CodeParamNotFound))
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "age", fieldErr.Field)
+	assert.Equal(t, CodeGreaterThanMax, fieldErr.Code)
+}
+
+func TestRequestErrors(t *testing.T) {
+	obj := multiErrorParam{}
+
+	req := request("POST", "/", "{", ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.True(t, errors.Is(err, ErrDecodeJson))
+
+	req = request("POST", "/", "", "text/plain")
+	err = Bind(req, &obj)
+	assert.True(t, errors.Is(err, ErrUnsupportedContentType))
+}
@@ -0,0 +1,194 @@
+package validator
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// stringFormat is a format of the `format` tag.
+type stringFormat struct {
+	// name is the format as written in error messages.
+	name  string
+	valid func(s string) bool
+}
+
+var (
+	uuidPattern   = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	e164Pattern   = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+	semverPattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+		`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+		`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+)
+
+// stringFormats are the formats of the `format` tag, by name.
+var stringFormats = map[string]stringFormat{
+	"email": {"email address", func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	}},
+	"uri": {"uri", func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	}},
+	"uuid": {"uuid", uuidPattern.MatchString},
+	"ipv4": {"ipv4 address", func(s string) bool {
+		return net.ParseIP(s) != nil && !strings.Contains(s, ":")
+	}},
+	"ipv6": {"ipv6 address", func(s string) bool {
+		return net.ParseIP(s) != nil && strings.Contains(s, ":")
+	}},
+	"cidr": {"cidr notation", func(s string) bool {
+		_, _, err := net.ParseCIDR(s)
+		return err == nil
+	}},
+	"hostname": {"hostname", isHostname},
+	"mac": {"mac address", func(s string) bool {
+		_, err := net.ParseMAC(s)
+		return err == nil
+	}},
+	"hex": {"hexadecimal string", func(s string) bool {
+		return s != "" && strings.Trim(s, "0123456789abcdefABCDEF") == ""
+	}},
+	"base64url": {"base64url string", func(s string) bool {
+		enc := base64.URLEncoding
+		if !strings.HasSuffix(s, "=") {
+			enc = base64.RawURLEncoding
+		}
+		_, err := enc.DecodeString(s)
+		return err == nil
+	}},
+	"semver": {"semantic version", semverPattern.MatchString},
+	"e164":   {"e164 phone number", e164Pattern.MatchString},
+	"iso3166": {"iso3166 country code", func(s string) bool {
+		return len(s) == 2 && strings.Contains(countryCodes, " "+s+" ")
+	}},
+	"iso4217": {"iso4217 currency code", func(s string) bool {
+		return len(s) == 3 && strings.Contains(currencyCodes, " "+s+" ")
+	}},
+	"creditcard": {"credit card number", isCreditCard},
+}
+
+// parseFormats parses the `format` tag, one or several formats separated by `|`.
+func (r *rules) parseFormats(tag reflect.StructTag, t, vt reflect.Type) {
+	param := tag.Get("format")
+	if len(param) == 0 {
+		return
+	}
+	if vt.Kind() != reflect.String {
+		r.notApplicable("format", param, t)
+		return
+	}
+	for _, name := range strings.Split(param, "|") {
+		f, ok := stringFormats[name]
+		if !ok {
+			r.fail(&FieldError{Tag: "format", Param: param, Code: CodeInvalidTag,
+				Message: fmt.Sprintf(ERR_INVALID_FORMAT_TAG, name)})
+			r.formats = nil
+			return
+		}
+		r.formats = append(r.formats, f)
+	}
+	r.formatParam = param
+}
+
+// validateFormat checks that s is in one of the formats of r.
+func validateFormat(s string, r *rules) *FieldError {
+	if len(r.formats) == 0 {
+		return nil
+	}
+	names := make([]string, len(r.formats))
+	for i, f := range r.formats {
+		if f.valid(s) {
+			return nil
+		}
+		names[i] = f.name
+	}
+	return &FieldError{Tag: "format", Param: r.formatParam, Value: s, Code: CodeInvalidFormat,
+		Message: fmt.Sprintf(ERR_INVALID_FORMAT, strings.Join(names, " or "))}
+}
+
+// isHostname reports whether s is a hostname as of RFC 1123.
+func isHostname(s string) bool {
+	if len(s) == 0 || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isCreditCard reports whether s is a card number of 12 to 19 digits with a
+// valid Luhn checksum.
+func isCreditCard(s string) bool {
+	if len(s) < 12 || len(s) > 19 {
+		return false
+	}
+	sum := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		d := int(s[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if (len(s)-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// countryCodes are the ISO 3166-1 alpha-2 country codes.
+const countryCodes = " " +
+	"AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ " +
+	"BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ " +
+	"CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ " +
+	"DE DJ DK DM DO DZ " +
+	"EC EE EG EH ER ES ET " +
+	"FI FJ FK FM FO FR " +
+	"GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY " +
+	"HK HM HN HR HT HU " +
+	"ID IE IL IM IN IO IQ IR IS IT " +
+	"JE JM JO JP " +
+	"KE KG KH KI KM KN KP KR KW KY KZ " +
+	"LA LB LC LI LK LR LS LT LU LV LY " +
+	"MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ " +
+	"NA NC NE NF NG NI NL NO NP NR NU NZ " +
+	"OM " +
+	"PA PE PF PG PH PK PL PM PN PR PS PT PW PY " +
+	"QA " +
+	"RE RO RS RU RW " +
+	"SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ " +
+	"TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ " +
+	"UA UG UM US UY UZ " +
+	"VA VC VE VG VI VN VU " +
+	"WF WS " +
+	"YE YT " +
+	"ZA ZM ZW "
+
+// currencyCodes are the ISO 4217 currency codes.
+const currencyCodes = " " +
+	"AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BOV BRL BSD BTN BWP BYN BZD " +
+	"CAD CDF CHE CHF CHW CLF CLP CNY COP COU CRC CUC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB EUR " +
+	"FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY " +
+	"KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK " +
+	"MXN MXV MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF " +
+	"SAR SBD SCR SDG SEK SGD SHP SLE SLL SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS " +
+	"UAH UGX USD USN UYI UYU UYW UZS VED VES VND VUV WST XAF XAG XAU XBA XBB XBC XBD XCD XCG XDR XOF XPD XPF " +
+	"XPT XSU XTS XUA XXX YER ZAR ZMW ZWG ZWL "
@@ -0,0 +1,92 @@
+package validator
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringFormats(t *testing.T) {
+	cases := []struct {
+		format  string
+		valid   []string
+		invalid []string
+	}{
+		{"email", []string{"tony@example.com", "a.b+c@sub.example.org"}, []string{"tony", "Tony <tony@example.com>", "@example.com"}},
+		{"uri", []string{"https://example.com/a?b=c", "mailto:tony@example.com"}, []string{"example.com", "/path", "http://[::1"}},
+		{"uuid", []string{"123e4567-e89b-12d3-a456-426614174000"}, []string{"123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g"}},
+		{"ipv4", []string{"192.168.0.1"}, []string{"::1", "256.0.0.1", "::ffff:192.168.0.1"}},
+		{"ipv6", []string{"::1", "2001:db8::68", "::ffff:192.168.0.1"}, []string{"192.168.0.1", "2001:db8:::1"}},
+		{"cidr", []string{"10.0.0.0/8", "2001:db8::/32"}, []string{"10.0.0.0", "10.0.0.0/33"}},
+		{"hostname", []string{"example.com", "localhost", "a-b.c0"}, []string{"-a.com", "a..com", "a_b.com", ""}},
+		{"mac", []string{"00:00:5e:00:53:01", "00-00-5E-00-53-01"}, []string{"00:00:5e:00:53", "zz:00:5e:00:53:01"}},
+		{"hex", []string{"deadBEEF", "0"}, []string{"0xff", "xyz"}},
+		{"base64url", []string{"aGVsbG8_", "aGk=", "aGk"}, []string{"aGVsbG8/", "a"}},
+		{"semver", []string{"1.0.0", "1.2.3-beta.1+build.5"}, []string{"1.0", "v1.0.0", "01.0.0"}},
+		{"e164", []string{"+33123456789", "+14155552671"}, []string{"0123456789", "+0123", "+1234567890123456"}},
+		{"iso3166", []string{"FR", "US"}, []string{"fr", "XX", "FRA"}},
+		{"iso4217", []string{"EUR", "USD"}, []string{"eur", "ABC", "EU"}},
+		{"creditcard", []string{"4111111111111111", "5500000000000004"}, []string{"4111111111111112", "4111-1111-1111-1111", "1234"}},
+	}
+	for _, c := range cases {
+		f := stringFormats[c.format]
+		for _, s := range c.valid {
+			assert.True(t, f.valid(s), "%s %q", c.format, s)
+		}
+		for _, s := range c.invalid {
+			assert.False(t, f.valid(s), "%s %q", c.format, s)
+		}
+	}
+}
+
+type formatParam struct {
+	Email   string   `form:"email" valid:"required" format:"email"`
+	Host    string   `form:"host" valid:"required" format:"ipv4|ipv6|hostname"`
+	Mirrors []string `form:"mirrors" valid:"optional" format:"uri"`
+}
+
+func TestFormat(t *testing.T) {
+	body := url.Values{}
+	body.Add("email", "tony@example.com")
+	body.Add("host", "::1")
+	body.Add("mirrors", "https://a.example.com")
+	body.Add("mirrors", "https://b.example.com")
+
+	obj := formatParam{}
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	assert.NoError(t, Bind(req, &obj))
+
+	body = url.Values{}
+	body.Add("email", "tony")
+	body.Add("host", "a_b")
+	body.Add("mirrors", "https://a.example.com")
+	body.Add("mirrors", "b.example.com")
+
+	obj = formatParam{}
+	req = request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "email: not a valid email address; "+
+		"host: not a valid ipv4 address or ipv6 address or hostname; "+
+		"mirrors: not a valid uri", err.Error())
+	assert.True(t, errors.Is(err, CodeInvalidFormat))
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "format", fieldErr.Tag)
+	assert.Equal(t, "email", fieldErr.Param)
+}
+
+type malformedFormatParam struct {
+	Email string `form:"email" valid:"required" format:"mail"`
+	Age   int    `form:"age" valid:"required" format:"hex"`
+}
+
+func TestFormatMalformedTags(t *testing.T) {
+	err := Compile(&malformedFormatParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "Email: invalid `format` tag, unknown format mail; "+
+		"Age: invalid `format` tag, not allowed on int", err.Error())
+}
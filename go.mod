@@ -1,5 +1,16 @@
 module github.com/VictorCPH/validator
 
-go 1.12
+go 1.20
 
-require github.com/stretchr/testify v1.3.0
+require (
+	github.com/pelletier/go-toml/v2 v2.1.0
+	github.com/rivo/uniseg v0.2.0
+	github.com/stretchr/testify v1.8.4
+	golang.org/x/text v0.13.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
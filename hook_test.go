@@ -0,0 +1,100 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type periodParam struct {
+	Start time.Time `form:"start" json:"start" valid:"required" time_format:"2006-01-02"`
+	End   time.Time `form:"end" json:"end" valid:"required" time_format:"2006-01-02"`
+}
+
+func (p *periodParam) Validate() error {
+	if !p.End.After(p.Start) {
+		return &FieldError{Field: "end", Code: "end_before_start", Message: "must be after start"}
+	}
+	return nil
+}
+
+type contactParam struct {
+	Email  string       `form:"email" json:"email" valid:"optional" default:"-"`
+	Phone  string       `form:"phone" json:"phone" valid:"optional" default:"-"`
+	Period *periodParam `form:"period" json:"period" valid:"optional"`
+}
+
+type ctxKey struct{}
+
+func (c *contactParam) Validate(ctx context.Context) error {
+	if c.Email == "-" && c.Phone == "-" {
+		return errors.New("either email or phone is required")
+	}
+	if banned, _ := ctx.Value(ctxKey{}).(string); banned != "" && banned == c.Email {
+		return Errors{{Field: "email", Code: "banned", Message: "is banned"}}
+	}
+	return nil
+}
+
+func TestValidateHook(t *testing.T) {
+	obj := contactParam{}
+
+	body := url.Values{}
+	body.Add("email", "a@b.c")
+	body.Add("period[start]", "2020-01-02")
+	body.Add("period[end]", "2020-01-03")
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.NoError(t, err)
+
+	obj = contactParam{}
+	req = request("POST", "/", url.Values{}.Encode(), ContentTypeForm)
+	err = Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "either email or phone is required", err.Error())
+	assert.True(t, errors.Is(err, CodeInvalidStruct))
+
+	obj = contactParam{}
+	body.Set("period[end]", "2020-01-01")
+	req = request("POST", "/", body.Encode(), ContentTypeForm)
+	req = req.WithContext(context.WithValue(req.Context(), ctxKey{}, "a@b.c"))
+	err = Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "period.end: must be after start; email: is banned", err.Error())
+}
+
+func TestValidateHookSkippedOnFieldErrors(t *testing.T) {
+	obj := contactParam{}
+
+	body := `{"period": {"start": "2020-01-02", "end": "2020-01-01"}, "email": "", "phone": "1"}`
+	req := request("POST", "/", body, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "email: blank string", err.Error())
+
+	obj = contactParam{}
+	body = `{"period": {"start": "2020-01-02", "end": "2020-01-01"}, "email": "a@b.c", "phone": "1"}`
+	req = request("POST", "/", body, ContentTypeJson)
+	err = Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "period.end: must be after start", err.Error())
+}
+
+func TestValidateHookItems(t *testing.T) {
+	type param struct {
+		Periods []*periodParam `json:"periods" xml:"period" valid:"required"`
+	}
+	body := `{"periods": [{"start": "2020-01-02", "end": "2020-01-03"}, null]}`
+	req := request("POST", "/", body, ContentTypeJson)
+	assert.NoError(t, Bind(req, &param{}))
+
+	body = `{"periods": [{"start": "2020-01-02", "end": "2020-01-03"}, {"start": "2020-01-02", "end": "2020-01-01"}]}`
+	req = request("POST", "/", body, ContentTypeJson)
+	err := Bind(req, &param{})
+	assert.Error(t, err)
+	assert.Equal(t, "periods.1.end: must be after start", err.Error())
+}
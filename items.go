@@ -0,0 +1,172 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// coercionTags are the tags driving how a param is coerced, they can not be
+// given to the elements of a list by the `dive` tag.
+var coercionTags = []string{"valid", "default", "type", "time_format", "transform", "dive"}
+
+// parseItems parses the `min_items`, `max_items` and `unique` tags of a
+// slice or a map, whose base type is bt, and the element rules of its `dive`
+// tag. It returns the type the other tags apply to: vt, the type of the
+// elements, or bt itself when the element rules are given by `dive`.
+func (r *rules) parseItems(tag reflect.StructTag, t, bt, vt reflect.Type) reflect.Type {
+	isList := bt.Kind() == reflect.Slice && !isCoercible(bt)
+	isCollection := isList || bt.Kind() == reflect.Map
+
+	if param := tag.Get("min_items"); len(param) != 0 {
+		if !isCollection {
+			r.notApplicable("min_items", param, t)
+		} else {
+			r.minItems = r.parseInt("min_items", param, ERR_INVALID_MIN_ITEMS_TAG)
+		}
+	}
+	if param := tag.Get("max_items"); len(param) != 0 {
+		if !isCollection {
+			r.notApplicable("max_items", param, t)
+		} else {
+			r.maxItems = r.parseInt("max_items", param, ERR_INVALID_MAX_ITEMS_TAG)
+		}
+	}
+	if param := tag.Get("unique"); len(param) != 0 {
+		unique, err := strconv.ParseBool(param)
+		if !isList {
+			r.notApplicable("unique", param, t)
+		} else if err != nil {
+			r.fail(&FieldError{Tag: "unique", Param: param, Code: CodeInvalidTag, Message: ERR_INVALID_UNIQUE_TAG})
+		} else {
+			r.unique = unique
+		}
+	}
+
+	param, ok := tag.Lookup("dive")
+	if !ok {
+		return vt
+	}
+	if !isCollection {
+		r.notApplicable("dive", param, t)
+		return vt
+	}
+	elemTag, ok := diveTag(param, r.timeFormat)
+	if !ok {
+		r.fail(&FieldError{Tag: "dive", Param: param, Code: CodeInvalidTag, Message: ERR_INVALID_DIVE_TAG})
+		return vt
+	}
+	r.elem = parseRules(elemTag, bt.Elem())
+	r.errs = append(r.errs, r.elem.errs...)
+	return bt
+}
+
+// diveRule matches the start of a rule of a `dive` tag.
+var diveRule = regexp.MustCompile(`^[a-z_]+=`)
+
+// diveTag converts the `dive` tag param, e.g. `min_len=3,regexp=^[a-z]{1,8}$`,
+// to the struct tag of an element. A comma followed by something else than a
+// rule is part of the value, such as in `{1,8}`. The `time_format` of the
+// list is kept, as elements are coerced by it.
+func diveTag(param string, timeFormat string) (reflect.StructTag, bool) {
+	var rules []string
+	for _, s := range strings.Split(param, ",") {
+		switch {
+		case diveRule.MatchString(s):
+			rules = append(rules, s)
+		case len(rules) > 0:
+			rules[len(rules)-1] += "," + s
+		default:
+			return "", false
+		}
+	}
+
+	tags := make([]string, 0, len(rules)+1)
+	for _, rule := range rules {
+		i := strings.Index(rule, "=")
+		if isIn(rule[:i], coercionTags) {
+			return "", false
+		}
+		tags = append(tags, fmt.Sprintf("%s:%q", rule[:i], rule[i+1:]))
+	}
+	if timeFormat != "" {
+		tags = append(tags, fmt.Sprintf("time_format:%q", timeFormat))
+	}
+	return reflect.StructTag(strings.Join(tags, " ")), true
+}
+
+// elemRules returns the rules of the elements or values of a list or map.
+func (r *rules) elemRules() *rules {
+	if r.elem != nil {
+		return r.elem
+	}
+	return r
+}
+
+// validateItems checks the number of items of the slice or map v, and that
+// the elements of a slice are unique. A nil one is absent, and not checked.
+func validateItems(v reflect.Value, r *rules) *FieldError {
+	if v.IsNil() {
+		return nil
+	}
+	if r.minItems != nil && int64(v.Len()) < r.minItems.i {
+		return &FieldError{Tag: "min_items", Param: r.minItems.param, Value: v.Len(),
+			Code: CodeTooFewItems, Message: fmt.Sprintf(ERR_TOO_FEW_ITEMS, r.minItems.i)}
+	}
+	if r.maxItems != nil && int64(v.Len()) > r.maxItems.i {
+		return &FieldError{Tag: "max_items", Param: r.maxItems.param, Value: v.Len(),
+			Code: CodeTooManyItems, Message: fmt.Sprintf(ERR_TOO_MANY_ITEMS, r.maxItems.i)}
+	}
+	if r.unique && v.Kind() == reflect.Slice {
+		seen := make(map[interface{}]bool, v.Len())
+		// Such as slices held by an []interface{}, compared one by one
+		var unhashable []interface{}
+		for i := 0; i < v.Len(); i++ {
+			e := v.Index(i).Interface()
+			var dup bool
+			if hashable(v.Index(i)) {
+				dup, seen[e] = seen[e], true
+			} else {
+				for _, u := range unhashable {
+					if dup = reflect.DeepEqual(u, e); dup {
+						break
+					}
+				}
+				unhashable = append(unhashable, e)
+			}
+			if dup {
+				return &FieldError{Tag: "unique", Param: "true", Value: e,
+					Code: CodeDuplicateItem, Message: fmt.Sprintf(ERR_DUPLICATE_ITEM, e)}
+			}
+		}
+	}
+	return nil
+}
+
+// hashable reports whether v can be used as a map key: its type is
+// comparable, and so are the values held by its interfaces.
+func hashable(v reflect.Value) bool {
+	if v.Kind() == reflect.Interface {
+		return v.IsNil() || hashable(v.Elem())
+	}
+	if !v.Type().Comparable() {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !hashable(v.Index(i)) {
+				return false
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !hashable(v.Field(i)) {
+				return false
+			}
+		}
+	}
+	return true
+}
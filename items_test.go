@@ -0,0 +1,160 @@
+package validator
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type itemsParam struct {
+	Friends []string          `form:"friends" json:"friends" valid:"required" min_items:"1" max_items:"3" unique:"true" min_len:"2"`
+	Scores  []int             `form:"scores" json:"scores" valid:"optional" max_items:"4" dive:"range=0|100"`
+	Codes   []string          `form:"codes" json:"codes" valid:"optional" dive:"regexp=^[A-Z]{1,3}$,max_len=3"`
+	Meta    map[string]string `form:"meta" json:"meta" valid:"optional" min_items:"2" dive:"max_len=4"`
+}
+
+func TestItems(t *testing.T) {
+	body := url.Values{}
+	body.Add("friends", "tony")
+	body.Add("friends", "anna")
+	body.Add("scores", "0")
+	body.Add("scores", "100")
+	body.Add("scores", "100")
+	body.Add("codes", "FR")
+	body.Add("meta[color]", "red")
+	body.Add("meta[size]", "xl")
+
+	obj := itemsParam{}
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, []int{0, 100, 100}, obj.Scores)
+	assert.Equal(t, []string{"FR"}, obj.Codes)
+}
+
+func TestItemsFailed(t *testing.T) {
+	body := url.Values{}
+	body.Add("friends", "tony")
+	body.Add("friends", "anna")
+	body.Add("friends", "tony")
+	body.Add("scores", "101")
+	body.Add("codes", "fr")
+	body.Add("meta[color]", "red")
+
+	obj := itemsParam{}
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "friends: duplicate item tony; scores: not in range (0, 100); "+
+		"codes: wrong format, shold match regexp `^[A-Z]{1,3}$`; meta: fewer than 2 items", err.Error())
+	assert.True(t, errors.Is(err, CodeDuplicateItem))
+	assert.True(t, errors.Is(err, CodeTooFewItems))
+
+	body = url.Values{}
+	body.Add("friends", "a")
+	body.Add("scores", "1")
+	body.Add("scores", "2")
+	body.Add("scores", "3")
+	body.Add("scores", "4")
+	body.Add("scores", "5")
+	body.Add("meta[color]", "green")
+	body.Add("meta[size]", "xl")
+
+	obj = itemsParam{}
+	req = request("POST", "/", body.Encode(), ContentTypeForm)
+	err = Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "friends: shorter than 2 characters; scores: more than 4 items; "+
+		"meta.color: longer than 4 characters", err.Error())
+}
+
+func TestItemsJson(t *testing.T) {
+	obj := itemsParam{}
+	req := request("POST", "/", `{"friends": []}`, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "friends: fewer than 1 items", err.Error())
+}
+
+func TestItemsUniqueJson(t *testing.T) {
+	type param struct {
+		Tags []interface{} `json:"tags" valid:"optional" unique:"true"`
+	}
+	req := request("POST", "/", `{"tags": [[1], [2], {"a": 1}, "[1]", 1]}`, ContentTypeJson)
+	assert.NoError(t, Bind(req, &param{}))
+
+	req = request("POST", "/", `{"tags": [[1], "x", [1]]}`, ContentTypeJson)
+	err := Bind(req, &param{})
+	assert.Error(t, err)
+	assert.Equal(t, "tags: duplicate item [1]", err.Error())
+	assert.True(t, errors.Is(err, CodeDuplicateItem))
+
+	req = request("POST", "/", `{"tags": [1, {"a": [1]}, {"a": [1]}]}`, ContentTypeJson)
+	err = Bind(req, &param{})
+	assert.Error(t, err)
+	assert.Equal(t, "tags: duplicate item map[a:[1]]", err.Error())
+}
+
+type orderLine struct {
+	SKU string `json:"sku" xml:"sku,attr" yaml:"sku" toml:"sku" valid:"required"`
+	Qty int    `json:"qty" xml:"qty" yaml:"qty" toml:"qty" valid:"optional" default:"1" min:"1"`
+}
+
+type orderParam struct {
+	Items []orderLine  `json:"items" xml:"item" yaml:"items" toml:"items" valid:"required" min_items:"1"`
+	Extra []*orderLine `json:"extra" xml:"extra" yaml:"extra" toml:"extra" valid:"optional"`
+}
+
+func TestItemsStructsJson(t *testing.T) {
+	obj := orderParam{}
+	req := request("POST", "/", `{"items": [{"sku": "a"}, {"sku": "b", "qty": 2}], "extra": [null, {"sku": "c"}]}`,
+		ContentTypeJson)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, []orderLine{{"a", 1}, {"b", 2}}, obj.Items)
+	assert.Nil(t, obj.Extra[0])
+	assert.Equal(t, orderLine{"c", 1}, *obj.Extra[1])
+
+	req = request("POST", "/", `{"items": [{"qty": 0}, {"sku": "b", "qty": 2}], "extra": [{"sku": ""}]}`,
+		ContentTypeJson)
+	err := Bind(req, &orderParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "items.0.sku: not found; items.0.qty: smaller than 1; extra.0.sku: blank string", err.Error())
+}
+
+type malformedItemsParam struct {
+	Name    string   `form:"name" valid:"required" min_items:"1"`
+	Tags    []string `form:"tags" valid:"required" unique:"yes" dive:"valid=required"`
+	Scores  []int    `form:"scores" valid:"required" min:"1" dive:"max=ten"`
+	Friends []string `form:"friends" valid:"required" dive:"{1,3}"`
+}
+
+func TestItemsMalformedTags(t *testing.T) {
+	err := Compile(&malformedItemsParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "Name: invalid `min_items` tag, not allowed on string; "+
+		"Tags: invalid `unique` tag, must be bool; "+
+		"Tags: invalid `dive` tag, must be a list of element tags such as `min_len=3,max_len=10`; "+
+		"Scores: invalid `max` tag, must be int or float; "+
+		"Scores: invalid `min` tag, not allowed on []int; "+
+		"Friends: invalid `dive` tag, must be a list of element tags such as `min_len=3,max_len=10`", err.Error())
+}
+
+type malformedOrderParam struct {
+	Lines []*malformedNestedParam `json:"lines" valid:"required"`
+	Items []orderLine             `form:"items" json:"items" valid:"optional"`
+}
+
+func TestItemsStructsMalformedTags(t *testing.T) {
+	err := Compile(&malformedOrderParam{})
+	assert.Error(t, err)
+	errs := err.(Errors)
+	assert.Len(t, errs, 3)
+	assert.Equal(t, "Lines.Zip", errs[0].Field)
+	assert.Equal(t, "Lines.Lines", errs[1].Field)
+	assert.Equal(t, "Items: invalid `form` tag, not allowed on []validator.orderLine", errs[2].Error())
+
+	req := request("GET", "/?items=x", "", "")
+	err = Bind(req, &malformedOrderParam{})
+	assert.True(t, errors.Is(err, ErrInvalidTag))
+}
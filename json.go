@@ -0,0 +1,475 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// decodeJson unmarshals the json object data into the struct val field by
+// field, so that tags like `time_format` are honored as they are for form,
+// then validates every field. As for form, an absent key, or a null value,
+// is reported for a required field and set to the `default` of an optional
+// one. Invalid fields are reported in errs, while a malformed body is
+// returned as error.
+//
+// In strict mode, see Strict, keys which do not map to a field are reported
+// as well.
+func decodeJson(data []byte, val reflect.Value, path string, strict bool, errs *Errors) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return jsonKindError(err, "object", path, errs)
+	}
+
+	s := schemaOf(val.Type())
+	if s.strict && !strict {
+		strict = true
+		checkDuplicateKeys(data, path, errs)
+	}
+	if err := decodeJsonObject(fields, val, s, path, strict, errs); err != nil {
+		return err
+	}
+	if strict {
+		checkUnknownKeys(fields, val.Type(), path, errs)
+	}
+	return nil
+}
+
+// decodeJsonObject decodes the fields of the struct val from the keys of a
+// json object.
+func decodeJsonObject(fields map[string]json.RawMessage, val reflect.Value, s *structSchema, path string,
+	strict bool, errs *Errors) error {
+	present := make(map[*fieldSchema]bool, len(s.fields))
+	for _, f := range s.fields {
+		field := val.Field(f.index)
+		name, ok := f.name("json")
+		if !ok {
+			continue
+		}
+		// Fields of an embedded struct are promoted
+		if f.embedded && name == "" {
+			if field.Kind() == reflect.Ptr {
+				if field.IsNil() {
+					field.Set(reflect.New(field.Type().Elem()))
+				}
+				field = field.Elem()
+			}
+			if err := decodeJsonObject(fields, field, schemaOf(field.Type()), path, strict, errs); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = val.Type().Field(f.index).Name
+		}
+		fieldPath := joinPath(path, name)
+
+		raw, ok := lookupJson(fields, name)
+		if ok && string(raw) == "null" {
+			// null resets the field as encoding/json does, and is handled as absent
+			if err := json.Unmarshal(raw, field.Addr().Interface()); err != nil {
+				return err
+			}
+			ok = false
+		}
+		present[f] = ok
+
+		n := len(*errs)
+		nested := f.kind == fieldNested && isJsonStruct(field.Type())
+		switch {
+		case !ok && nested:
+			if err := decodeAbsentJson(field, f.rules, fieldPath, strict, errs); err != nil {
+				return err
+			}
+			continue
+		case !ok:
+			if err := coerceField(field, nil, f.rules, nil, nil); err != nil {
+				if err.Code != codeOptionalParamNotFound {
+					errs.add(fieldPath, err)
+				}
+				continue
+			}
+		case f.quoted:
+			if err := decodeJsonQuoted(raw, field, f.rules); err != nil {
+				errs.add(fieldPath, err)
+				continue
+			}
+		default:
+			if err := decodeJsonField(raw, field, f.rules, fieldPath, strict, errs); err != nil {
+				return err
+			}
+		}
+		if len(*errs) > n {
+			continue
+		}
+
+		switch {
+		case nested:
+			// Validated field by field, only registered rules are left
+		case f.kind == fieldMap:
+			validateMap(field, f.rules, fieldPath, errs)
+			if len(*errs) > n {
+				continue
+			}
+		default:
+			if err := validateField(field, f.rules); err != nil {
+				errs.add(fieldPath, err)
+				continue
+			}
+		}
+		if err := validateRules(field, f, val, fieldPath); err != nil {
+			errs.add(fieldPath, err)
+		}
+	}
+
+	sent := func(f *fieldSchema) bool { return present[f] }
+	validateConditions(val, s, path, "json", sent, errs)
+	validateComparisons(val, s, path, "json", sent, errs)
+	return nil
+}
+
+// decodeAbsentJson handles a struct or pointer-to-struct field whose key is
+// absent: it is reported if required, and left alone if optional or a pointer.
+// Otherwise its fields are decoded from an empty object, as is done for form.
+func decodeAbsentJson(val reflect.Value, r *rules, path string, strict bool, errs *Errors) error {
+	switch r.valid {
+	case "required":
+		errs.add(path, &FieldError{Tag: "valid", Param: "required", Code: CodeParamNotFound,
+			Message: ERR_PARAM_NOT_FOUND})
+		return nil
+	case "optional":
+		return nil
+	default:
+		if r.condition != nil || val.Kind() == reflect.Ptr {
+			return nil
+		}
+	}
+	return decodeJson([]byte("{}"), val, path, strict, errs)
+}
+
+func decodeJsonField(raw json.RawMessage, val reflect.Value, r *rules, path string, strict bool,
+	errs *Errors) error {
+	if string(raw) == "null" {
+		return json.Unmarshal(raw, val.Addr().Interface())
+	}
+
+	t := val.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == typeOfTime || (t == typeOfDuration || isCoercible(t)) && raw[0] == '"':
+		param := jsonParam(raw)
+		if err := setValue(val, param, r); err != nil {
+			errs.add(path, invalidParam(val, param, r))
+		}
+		return nil
+	case isJsonStruct(t):
+		if val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				val.Set(reflect.New(t))
+			}
+			val = val.Elem()
+		}
+		return decodeJson(raw, val, path, strict, errs)
+	case isJsonParams(t, raw):
+		if val.Kind() == reflect.Ptr {
+			val.Set(reflect.New(t))
+			val = val.Elem()
+		}
+		return decodeJsonParams(raw, val, r, path, errs)
+	case t.Kind() == reflect.Slice && isJsonStruct(t.Elem()):
+		if val.Kind() == reflect.Ptr {
+			val.Set(reflect.New(t))
+			val = val.Elem()
+		}
+		return decodeJsonItems(raw, val, path, strict, errs)
+	case isStructMap(t) && isJsonStruct(t.Elem()):
+		if val.Kind() == reflect.Ptr {
+			val.Set(reflect.New(t))
+			val = val.Elem()
+		}
+		return decodeJsonEntries(raw, val, path, strict, errs)
+	default:
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		if strict {
+			// Such as structs held by a map
+			dec.DisallowUnknownFields()
+		}
+		if err := dec.Decode(val.Addr().Interface()); err != nil {
+			if key := unknownFieldError(err); key != "" {
+				errs.add(path, &FieldError{Code: CodeUnknownField, Message: fmt.Sprintf(ERR_UNKNOWN_NESTED_FIELD, key)})
+				return nil
+			}
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				errs.add(path, jsonTypeError(typeErr, r))
+				return nil
+			}
+			return err
+		}
+		transformStrings(val, r)
+		return nil
+	}
+}
+
+// decodeJsonItems decodes the json array raw into val, a slice of structs,
+// item by item with decodeJson, so that each struct is validated at its
+// index, e.g. `items.0.sku`.
+func decodeJsonItems(raw json.RawMessage, val reflect.Value, path string, strict bool, errs *Errors) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return jsonKindError(err, "array", path, errs)
+	}
+	slice := reflect.MakeSlice(val.Type(), len(items), len(items))
+	for i, item := range items {
+		if string(item) == "null" {
+			continue
+		}
+		elem := slice.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			elem.Set(reflect.New(elem.Type().Elem()))
+			elem = elem.Elem()
+		}
+		if err := decodeJson(item, elem, joinPath(path, strconv.Itoa(i)), strict, errs); err != nil {
+			return err
+		}
+	}
+	val.Set(slice)
+	return nil
+}
+
+// decodeJsonEntries decodes the json object raw into val, a map of structs,
+// value by value with decodeJson, so that each struct is validated at its
+// key, e.g. `lines.a.sku`.
+func decodeJsonEntries(raw json.RawMessage, val reflect.Value, path string, strict bool, errs *Errors) error {
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return jsonKindError(err, "object", path, errs)
+	}
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	m := reflect.MakeMapWithSize(val.Type(), len(entries))
+	for _, key := range keys {
+		elem := reflect.New(val.Type().Elem()).Elem()
+		if entry := entries[key]; string(entry) != "null" {
+			field := elem
+			if field.Kind() == reflect.Ptr {
+				field.Set(reflect.New(field.Type().Elem()))
+				field = field.Elem()
+			}
+			if err := decodeJson(entry, field, joinPath(path, key), strict, errs); err != nil {
+				return err
+			}
+		}
+		m.SetMapIndex(reflect.ValueOf(key).Convert(val.Type().Key()), elem)
+	}
+	val.Set(m)
+	return nil
+}
+
+// jsonKindError reports err, returned when the json value at path is not of
+// the kind want, e.g. a string for a nested object, as an invalid field. It
+// is returned as is for the root, or a malformed value.
+func jsonKindError(err error, want string, path string, errs *Errors) error {
+	var typeErr *json.UnmarshalTypeError
+	if path == "" || !errors.As(err, &typeErr) {
+		return err
+	}
+	errs.add(path, &FieldError{Value: typeErr.Value, Code: CodeParamInvalid,
+		Message: fmt.Sprintf(ERR_PARAM_INVALID, want)})
+	return nil
+}
+
+// jsonTypeError converts err, a json value of the wrong type, into the error
+// of a form param which can not be coerced, such as `300 overflows uint8`.
+func jsonTypeError(err *json.UnmarshalTypeError, r *rules) *FieldError {
+	val := reflect.New(err.Type).Elem()
+	// Numbers are given as e.g. `number 300`, other values by their type
+	param := strings.TrimPrefix(err.Value, "number ")
+	if param != err.Value && setValue(val, param, r) == errOverflow {
+		return overflowParam(val, param)
+	}
+	return invalidParam(val, param, r)
+}
+
+// jsonParam returns the json scalar raw as a form param: the value of a
+// string, or the text of a number such as a Unix timestamp.
+func jsonParam(raw json.RawMessage) string {
+	var param string
+	if err := json.Unmarshal(raw, &param); err != nil {
+		return string(raw)
+	}
+	return param
+}
+
+// isJsonParams reports whether the values of raw, for the slice or map type
+// t, are decoded as form params rather than by encoding/json, such as times
+// which honor `time_format`, or strings of custom types unknown to
+// encoding/json.
+func isJsonParams(t reflect.Type, raw json.RawMessage) bool {
+	switch {
+	case t.Kind() == reflect.Slice && !isCoercible(t):
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+	default:
+		return false
+	}
+	et := t.Elem()
+	if et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	if et == typeOfTime || et == typeOfDuration {
+		return true
+	}
+	// As for a single value, custom types are coerced from strings only
+	return isCoercible(et) && !reflect.PtrTo(et).Implements(typeOfJsonUnmarshaler) && jsonStrings(raw)
+}
+
+// jsonStrings reports whether raw is a json array or object of strings.
+func jsonStrings(raw json.RawMessage) bool {
+	var values []json.RawMessage
+	if raw[0] == '{' {
+		var entries map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return false
+		}
+		for _, v := range entries {
+			values = append(values, v)
+		}
+	} else if err := json.Unmarshal(raw, &values); err != nil {
+		return false
+	}
+	for _, v := range values {
+		if v[0] != '"' {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeJsonParams decodes raw, a json array or object of scalars, into val,
+// a slice or map, value by value with setParams as form params are. Invalid
+// values of a map are reported at their key.
+func decodeJsonParams(raw json.RawMessage, val reflect.Value, r *rules, path string, errs *Errors) error {
+	et := val.Type().Elem()
+	if et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	param := func(raw json.RawMessage) string {
+		// Durations are also sent as json numbers of nanoseconds
+		if et == typeOfDuration && raw[0] != '"' {
+			return string(raw) + "ns"
+		}
+		return jsonParam(raw)
+	}
+
+	if val.Kind() == reflect.Slice {
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return err
+		}
+		params := make([]string, len(items))
+		for i, item := range items {
+			params[i] = param(item)
+		}
+		if err := setParams(val, params, r); err != nil {
+			errs.add(path, err)
+		}
+		return nil
+	}
+
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	m := reflect.MakeMapWithSize(val.Type(), len(entries))
+	for _, key := range keys {
+		v := reflect.New(val.Type().Elem()).Elem()
+		if err := setParams(v, []string{param(entries[key])}, r); err != nil {
+			errs.add(joinPath(path, key), err)
+			continue
+		}
+		m.SetMapIndex(reflect.ValueOf(key).Convert(val.Type().Key()), v)
+	}
+	val.Set(m)
+	return nil
+}
+
+var typeOfJsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// isJsonStruct reports whether the struct or pointer-to-struct type t is
+// decoded field by field, rather than by its own json.Unmarshaler.
+func isJsonStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return isNestedStruct(t) && !reflect.PtrTo(t).Implements(typeOfJsonUnmarshaler)
+}
+
+// jsonQuoted reports whether the field sf has the `,string` json option,
+// which encoding/json only honors for strings, numbers and booleans.
+func jsonQuoted(sf reflect.StructField) bool {
+	opts := strings.Split(sf.Tag.Get("json"), ",")[1:]
+	if !isIn("string", opts) {
+		return false
+	}
+	t := sf.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// decodeJsonQuoted decodes raw, the json string holding the value of a field
+// with the `,string` option, into val. The value is coerced as a form param,
+// but for a string which is itself a quoted json string, as encoding/json
+// expects.
+func decodeJsonQuoted(raw json.RawMessage, val reflect.Value, r *rules) *FieldError {
+	var param string
+	if err := json.Unmarshal(raw, &param); err != nil {
+		return invalidParam(val, string(raw), r)
+	}
+	if elemType(val.Type()).Kind() == reflect.String {
+		var s string
+		if err := json.Unmarshal([]byte(param), &s); err != nil {
+			return invalidParam(val, param, r)
+		}
+		param = s
+	}
+	return setParams(val, []string{param}, r)
+}
+
+// lookupJson returns the value of the key name, preferring an exact match
+// but accepting a case-insensitive one as encoding/json does.
+func lookupJson(fields map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if raw, ok := fields[name]; ok {
+		return raw, true
+	}
+	for key, raw := range fields {
+		if strings.EqualFold(key, name) {
+			return raw, true
+		}
+	}
+	return nil, false
+}
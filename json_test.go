@@ -1,7 +1,10 @@
 package validator
 
 import (
+	"encoding/json"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -45,3 +48,91 @@ func TestJson(t *testing.T) {
 	assert.Equal(t, float32(0.2), obj.Scores[1])
 	assert.Equal(t, "hello world", obj.ExtraInfo)
 }
+
+type jsonPresenceParam struct {
+	Name     string            `json:"name" valid:"required"`
+	Age      int               `json:"age" valid:"required"`
+	Greeting string            `json:"greeting" valid:"optional" default:"hello"`
+	Limit    *int              `json:"limit" valid:"optional" default:"20"`
+	Since    time.Time         `json:"since" valid:"optional" default:"2020-01-01" time_format:"2006-01-02"`
+	Nickname string            `json:"nickname" valid:"optional" min_len:"3"`
+	Tags     []string          `json:"tags" valid:"optional"`
+	Meta     map[string]string `json:"meta" valid:"required"`
+	Address  *addressParam     `json:"address" valid:"optional"`
+}
+
+func TestJsonPresence(t *testing.T) {
+	obj := jsonPresenceParam{}
+	req := request("POST", "/", `{"name": "Tony", "age": 0, "meta": {"a": "b"}}`, ContentTypeJson)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, 0, obj.Age)
+	assert.Equal(t, "hello", obj.Greeting)
+	assert.Equal(t, 20, *obj.Limit)
+	assert.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), obj.Since)
+	assert.Equal(t, "", obj.Nickname)
+	assert.Nil(t, obj.Tags)
+	assert.Nil(t, obj.Address)
+
+	obj = jsonPresenceParam{}
+	req = request("POST", "/", `{"name": "Tony", "age": 1, "greeting": "hi", "limit": 5, "meta": {}}`, ContentTypeJson)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "hi", obj.Greeting)
+	assert.Equal(t, 5, *obj.Limit)
+}
+
+func TestJsonPresenceFailed(t *testing.T) {
+	obj := jsonPresenceParam{}
+	req := request("POST", "/", `{"age": null, "nickname": "", "address": {}}`, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "name: not found; age: not found; nickname: blank string; meta: not found; "+
+		"address.city: not found; address.zip: not found", err.Error())
+	assert.True(t, errors.Is(err, CodeParamNotFound))
+	assert.Equal(t, "unknown", obj.Address.Street)
+}
+
+// money decodes itself from a json number of units, as cents.
+type money struct {
+	Cents int64
+}
+
+func (m *money) UnmarshalJSON(data []byte) error {
+	var units float64
+	if err := json.Unmarshal(data, &units); err != nil {
+		return err
+	}
+	m.Cents = int64(units * 100)
+	return nil
+}
+
+type jsonOptionParam struct {
+	ID     int    `json:"id,string" valid:"required" min:"1"`
+	Code   string `json:"code,string" valid:"optional"`
+	Active *bool  `json:"active,string" valid:"optional"`
+	Price  money  `json:"price" valid:"required"`
+	Fee    *money `json:"fee" valid:"optional"`
+}
+
+func TestJsonOptions(t *testing.T) {
+	obj := jsonOptionParam{}
+	body := `{"id": "42", "code": "\"a1\"", "active": "true", "price": 1.5, "fee": 0.25}`
+	req := request("POST", "/", body, ContentTypeJson)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, 42, obj.ID)
+	assert.Equal(t, "a1", obj.Code)
+	assert.Equal(t, true, *obj.Active)
+	assert.Equal(t, int64(150), obj.Price.Cents)
+	assert.Equal(t, int64(25), obj.Fee.Cents)
+
+	obj = jsonOptionParam{}
+	req = request("POST", "/", `{"id": 42}`, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "id: int expected; price: not found", err.Error())
+	assert.Nil(t, obj.Fee)
+
+	req = request("POST", "/", `{"id": "0", "price": 1}`, ContentTypeJson)
+	err = Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "id: smaller than 1", err.Error())
+}
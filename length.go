@@ -0,0 +1,77 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// parseLengths parses the `min_len`, `max_len` and `len` tags of a string
+// field, and the `len_unit` they are counted in.
+func (r *rules) parseLengths(tag reflect.StructTag, t, vt reflect.Type) {
+	r.lenUnit = tag.Get("len_unit")
+	switch r.lenUnit {
+	case "":
+		r.lenUnit = "runes"
+	case "runes", "bytes", "graphemes":
+	default:
+		r.fail(&FieldError{Tag: "len_unit", Param: r.lenUnit, Code: CodeInvalidTag, Message: ERR_INVALID_LEN_UNIT_TAG})
+	}
+
+	for _, l := range []struct {
+		name  string
+		msg   string
+		bound **bound
+	}{
+		{"min_len", ERR_INVALID_MIN_LEN_TAG, &r.minLen},
+		{"max_len", ERR_INVALID_MAX_LEN_TAG, &r.maxLen},
+		{"len", ERR_INVALID_LEN_TAG, &r.length},
+	} {
+		param := tag.Get(l.name)
+		if len(param) == 0 {
+			continue
+		}
+		if vt.Kind() != reflect.String {
+			r.notApplicable(l.name, param, t)
+			continue
+		}
+		*l.bound = r.parseInt(l.name, param, l.msg)
+	}
+}
+
+// countLength returns the length of s in unit.
+func countLength(s string, unit string) int {
+	switch unit {
+	case "bytes":
+		return len(s)
+	case "graphemes":
+		return uniseg.GraphemeClusterCount(s)
+	}
+	return utf8.RuneCountInString(s)
+}
+
+// lengthUnits name the units of the `len_unit` tag in error messages.
+var lengthUnits = map[string]string{"runes": "characters", "bytes": "bytes", "graphemes": "characters"}
+
+func validateLength(s string, r *rules) *FieldError {
+	if r.minLen == nil && r.maxLen == nil && r.length == nil {
+		return nil
+	}
+	n := int64(countLength(s, r.lenUnit))
+	unit := lengthUnits[r.lenUnit]
+	if r.length != nil && n != r.length.i {
+		return &FieldError{Tag: "len", Param: r.length.param, Value: s, Code: CodeWrongLength,
+			Message: fmt.Sprintf(ERR_WRONG_LENGTH, r.length.i, unit)}
+	}
+	if r.minLen != nil && n < r.minLen.i {
+		return &FieldError{Tag: "min_len", Param: r.minLen.param, Value: s, Code: CodeTooShort,
+			Message: fmt.Sprintf(ERR_TOO_SHORT, r.minLen.i, unit)}
+	}
+	if r.maxLen != nil && n > r.maxLen.i {
+		return &FieldError{Tag: "max_len", Param: r.maxLen.param, Value: s, Code: CodeTooLong,
+			Message: fmt.Sprintf(ERR_TOO_LONG, r.maxLen.i, unit)}
+	}
+	return nil
+}
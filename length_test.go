@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type lengthParam struct {
+	Username string   `form:"username" valid:"required" min_len:"3" max_len:"8"`
+	Code     string   `form:"code" valid:"optional" len:"4" len_unit:"bytes"`
+	Emoji    string   `form:"emoji" valid:"optional" max_len:"2" len_unit:"graphemes"`
+	Tags     []string `form:"tags" valid:"optional" max_len:"5"`
+}
+
+func TestLength(t *testing.T) {
+	body := url.Values{}
+	body.Add("username", "Zoë")
+	body.Add("code", "ab12")
+	body.Add("emoji", "👍🏽🇫🇷")
+	body.Add("tags", "go")
+	body.Add("tags", "json")
+
+	obj := lengthParam{}
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "👍🏽🇫🇷", obj.Emoji)
+}
+
+func TestLengthFailed(t *testing.T) {
+	body := url.Values{}
+	body.Add("username", "Tonyyyyyy")
+	body.Add("code", "é1")
+	body.Add("emoji", "👍🏽🇫🇷a")
+	body.Add("tags", "go")
+	body.Add("tags", "golang")
+
+	obj := lengthParam{}
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "username: longer than 8 characters; code: not 4 bytes long; "+
+		"emoji: longer than 2 characters; tags: longer than 5 characters", err.Error())
+	assert.True(t, errors.Is(err, CodeTooLong))
+	assert.True(t, errors.Is(err, CodeWrongLength))
+
+	obj = lengthParam{}
+	req = request("POST", "/", `{"Username": "Zo"}`, ContentTypeJson)
+	err = Bind(req, &obj)
+	assert.Error(t, err)
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, CodeTooShort, fieldErr.Code)
+	assert.Equal(t, "min_len", fieldErr.Tag)
+	assert.Equal(t, "shorter than 3 characters", fieldErr.Message)
+}
+
+type malformedLengthParam struct {
+	Name string `form:"name" valid:"required" min_len:"-1" len_unit:"words"`
+	Age  int    `form:"age" valid:"required" max_len:"3"`
+}
+
+func TestLengthMalformedTags(t *testing.T) {
+	err := Compile(&malformedLengthParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "Name: invalid `len_unit` tag, must be `runes`, `bytes` or `graphemes`; "+
+		"Name: invalid `min_len` tag, must be int; "+
+		"Age: invalid `max_len` tag, not allowed on int", err.Error())
+}
@@ -0,0 +1,116 @@
+package validator
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mapParam struct {
+	Meta   map[string]string   `form:"meta" json:"meta" valid:"required" keys:"color|size" regexp:"^[a-z0-9]+$"`
+	Counts map[string]int      `form:"counts" json:"counts" valid:"optional" max_items:"2" max:"10"`
+	Labels map[string][]string `form:"labels" json:"labels" valid:"optional"`
+}
+
+func TestMapForm(t *testing.T) {
+	obj := mapParam{}
+
+	body := url.Values{}
+	body.Add("meta[color]", "red")
+	body.Add("meta.size", "10")
+	body.Add("counts[a]", "1")
+	body.Add("labels[x][]", "one")
+	body.Add("labels[x][]", "two")
+	req := request("GET", "/?"+body.Encode(), "", "")
+	err := Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"color": "red", "size": "10"}, obj.Meta)
+	assert.Equal(t, map[string]int{"a": 1}, obj.Counts)
+	assert.Equal(t, map[string][]string{"x": {"one", "two"}}, obj.Labels)
+}
+
+func TestMapFormErrors(t *testing.T) {
+	obj := mapParam{}
+
+	req := request("POST", "/", url.Values{}.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "meta: not found", err.Error())
+
+	body := url.Values{}
+	body.Add("meta[color]", "RED")
+	body.Add("meta[shape]", "round")
+	body.Add("counts[a]", "11")
+	body.Add("counts[b]", "x")
+	req = request("POST", "/", body.Encode(), ContentTypeForm)
+	err = Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "meta.color: wrong format, shold match regexp `^[a-z0-9]+$`; "+
+		"meta.shape: key shape is not in [color size]; counts.b: int expected", err.Error())
+}
+
+func TestMapJson(t *testing.T) {
+	obj := mapParam{}
+
+	body := `{"meta": {"color": "red"}, "counts": {"a": 1, "b": 11, "c": 3}}`
+	req := request("POST", "/", body, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "counts: more than 2 items", err.Error())
+
+	obj = mapParam{}
+	body = `{"meta": {"color": "red"}, "counts": {"a": 1, "b": 11}}`
+	req = request("POST", "/", body, ContentTypeJson)
+	err = Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "counts.b: greater than 10", err.Error())
+}
+
+type structMapParam struct {
+	Lines   map[string]orderLine    `json:"lines" xml:"lines" yaml:"lines" valid:"required" keys:"a|b"`
+	Periods map[string]*periodParam `json:"periods" xml:"-" yaml:"-" valid:"optional"`
+}
+
+func TestMapStructs(t *testing.T) {
+	obj := structMapParam{}
+	body := `{"lines": {"a": {"sku": "x"}, "b": {"sku": "y", "qty": 2}},
+		"periods": {"q1": {"start": "2020-01-01", "end": "2020-03-31"}}}`
+	req := request("POST", "/", body, ContentTypeJson)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, map[string]orderLine{"a": {"x", 1}, "b": {"y", 2}}, obj.Lines)
+	assert.Equal(t, 2020, obj.Periods["q1"].End.Year())
+
+	body = `{"lines": {"a": {}, "b": {"sku": "y", "qty": 0}}}`
+	req = request("POST", "/", body, ContentTypeJson)
+	err := Bind(req, &structMapParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "lines.a.sku: not found; lines.b.qty: smaller than 1", err.Error())
+
+	body = `{"lines": {"c": {"sku": "z"}}, "periods": {"q1": {"start": "2020-01-02", "end": "2020-01-01"}}}`
+	req = request("POST", "/", body, ContentTypeJson)
+	err = Bind(req, &structMapParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "lines.c: key c is not in [a b]", err.Error())
+
+	body = `{"lines": {"a": {"sku": "x"}}, "periods": {"q1": {"start": "2020-01-02", "end": "2020-01-01"}}}`
+	req = request("POST", "/", body, ContentTypeJson)
+	err = Bind(req, &structMapParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "periods.q1.end: must be after start", err.Error())
+
+	req = request("POST", "/", "lines:\n  a: {sku: x}\n  b: {qty: 2}\n", ContentTypeYaml)
+	err = Bind(req, &structMapParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "lines.b.sku: not found (line 3)", err.Error())
+
+	req = request("POST", "/", `<order><lines><a sku="x"/><b><qty>0</qty></b></lines></order>`, ContentTypeXml)
+	err = Bind(req, &structMapParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "lines.b.sku: not found; lines.b.qty: smaller than 1", err.Error())
+
+	type formParam struct {
+		Lines map[string]orderLine `form:"lines" valid:"optional"`
+	}
+	assert.Error(t, Compile(&formParam{}))
+}
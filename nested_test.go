@@ -0,0 +1,137 @@
+package validator
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type addressParam struct {
+	City   string `form:"city" json:"city" valid:"required"`
+	Street string `form:"street" json:"street" valid:"optional" default:"unknown"`
+	Zip    int    `form:"zip" json:"zip" valid:"required" range:"10000|99999"`
+}
+
+type nestedParam struct {
+	Name    string        `form:"name" json:"name" valid:"required"`
+	Address addressParam  `form:"address" json:"address" valid:"required"`
+	Billing *addressParam `form:"billing" json:"billing" valid:"optional"`
+}
+
+func TestNestedForm(t *testing.T) {
+	obj := nestedParam{}
+
+	body := url.Values{}
+	body.Add("name", "Tony")
+	body.Add("address.city", "Shenzhen")
+	body.Add("address[zip]", "51800")
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "Shenzhen", obj.Address.City)
+	assert.Equal(t, "unknown", obj.Address.Street)
+	assert.Equal(t, 51800, obj.Address.Zip)
+	assert.Nil(t, obj.Billing)
+
+	obj = nestedParam{}
+	body.Add("billing[city]", "Beijing")
+	body.Add("billing[zip]", "10000")
+	req = request("GET", "/?"+body.Encode(), "", "")
+	err = Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "Beijing", obj.Billing.City)
+	assert.Equal(t, 10000, obj.Billing.Zip)
+}
+
+func TestNestedFormErrors(t *testing.T) {
+	obj := nestedParam{}
+
+	body := url.Values{}
+	body.Add("name", "Tony")
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "address: not found", err.Error())
+
+	body.Add("address[zip]", "100")
+	body.Add("billing.city", "Beijing")
+	req = request("POST", "/", body.Encode(), ContentTypeForm)
+	err = Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "address.city: not found; address.zip: not in range (10000, 99999); billing.zip: not found",
+		err.Error())
+}
+
+func TestNestedJson(t *testing.T) {
+	obj := nestedParam{}
+
+	body := `{
+		"name": "Tony",
+		"address": {"city": "", "street": "Nanshan", "zip": 100},
+		"billing": {"city": "Beijing", "street": "Haidian", "zip": 10000}
+	}`
+	req := request("POST", "/", body, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "address.city: blank string; address.zip: not in range (10000, 99999)", err.Error())
+	assert.Equal(t, "Beijing", obj.Billing.City)
+}
+
+func TestNestedJsonTypes(t *testing.T) {
+	body := `{"name": "Tony", "address": "x", "billing": [1]}`
+	req := request("POST", "/", body, ContentTypeJson)
+	err := Bind(req, &nestedParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "address: object expected; billing: object expected", err.Error())
+	assert.True(t, errors.Is(err, CodeParamInvalid))
+
+	body = `{"items": {"a": 1}, "extra": ["x"]}`
+	req = request("POST", "/", body, ContentTypeJson)
+	err = Bind(req, &orderParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "items: array expected; extra.0: object expected", err.Error())
+
+	req = request("POST", "/", `["x"]`, ContentTypeJson)
+	assert.True(t, errors.Is(Bind(req, &nestedParam{}), ErrDecodeJson))
+}
+
+type nodeParam struct {
+	Name  string     `form:"name" json:"name" xml:"name" yaml:"name" toml:"name"`
+	Child *nodeParam `form:"child" json:"child" xml:"child" yaml:"child" toml:"child"`
+}
+
+func TestNestedRecursive(t *testing.T) {
+	obj := nodeParam{}
+
+	req := request("GET", "/?name=x", "", "")
+	err := Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "x", obj.Name)
+	assert.Nil(t, obj.Child)
+
+	obj = nodeParam{}
+	req = request("GET", "/?name=x&child.name=y", "", "")
+	err = Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "y", obj.Child.Name)
+	assert.Nil(t, obj.Child.Child)
+}
+
+func TestNestedRecursiveJson(t *testing.T) {
+	obj := nodeParam{}
+
+	req := request("POST", "/", `{"name": "x"}`, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "x", obj.Name)
+	assert.Nil(t, obj.Child)
+
+	obj = nodeParam{}
+	req = request("POST", "/", `{"name": "x", "child": {"name": "y"}}`, ContentTypeJson)
+	err = Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "y", obj.Child.Name)
+	assert.Nil(t, obj.Child.Child)
+}
@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type numericParam struct {
+	ID     int64    `form:"id" json:"id" valid:"required" min:"1"`
+	Level  int8     `form:"level" json:"level" valid:"required" range:"-5|5"`
+	Small  int16    `form:"small" json:"small" valid:"optional"`
+	Medium int32    `form:"medium" json:"medium" valid:"optional"`
+	Count  uint32   `form:"count" json:"count" valid:"required" max:"1000"`
+	Flags  uint8    `form:"flags" json:"flags" valid:"optional"`
+	Size   uint     `form:"size" json:"size" valid:"optional" range:"1|10"`
+	Ptr    uintptr  `form:"ptr" json:"ptr" valid:"optional"`
+	Big    uint64   `form:"big" json:"big" valid:"optional"`
+	Tags   []uint16 `form:"tags" json:"tags" valid:"optional" max:"100"`
+}
+
+func TestNumericKinds(t *testing.T) {
+	obj := numericParam{}
+
+	body := url.Values{}
+	body.Add("id", "9223372036854775807")
+	body.Add("level", "-5")
+	body.Add("small", "-32768")
+	body.Add("medium", "2147483647")
+	body.Add("count", "1000")
+	body.Add("flags", "255")
+	body.Add("size", "10")
+	body.Add("ptr", "4096")
+	body.Add("big", "18446744073709551615")
+	body.Add("tags[]", "1")
+	body.Add("tags[]", "100")
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9223372036854775807), obj.ID)
+	assert.Equal(t, int8(-5), obj.Level)
+	assert.Equal(t, int16(-32768), obj.Small)
+	assert.Equal(t, int32(2147483647), obj.Medium)
+	assert.Equal(t, uint32(1000), obj.Count)
+	assert.Equal(t, uint8(255), obj.Flags)
+	assert.Equal(t, uint(10), obj.Size)
+	assert.Equal(t, uintptr(4096), obj.Ptr)
+	assert.Equal(t, uint64(18446744073709551615), obj.Big)
+	assert.Equal(t, []uint16{1, 100}, obj.Tags)
+}
+
+func TestNumericOverflow(t *testing.T) {
+	obj := numericParam{}
+
+	body := url.Values{}
+	body.Add("id", "9223372036854775808")
+	body.Add("level", "128")
+	body.Add("count", "-1")
+	body.Add("flags", "300")
+	body.Add("tags[]", "65536")
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "id: 9223372036854775808 overflows int64; level: 128 overflows int8; count: uint32 expected; "+
+		"flags: 300 overflows uint8; tags: 65536 overflows uint16", err.Error())
+	assert.Equal(t, CodeParamOverflow, err.(Errors)[0].Code)
+}
+
+func TestNumericOverflowJson(t *testing.T) {
+	obj := numericParam{}
+
+	body := `{"id": 9223372036854775808, "level": 128, "count": -1, "flags": 300, "size": 1.5, "tags": [1, 65536],
+		"medium": "7"}`
+	req := request("POST", "/", body, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "id: 9223372036854775808 overflows int64; level: 128 overflows int8; medium: int32 expected; "+
+		"count: uint32 expected; flags: 300 overflows uint8; size: uint expected; tags: 65536 overflows uint16",
+		err.Error())
+	errs := err.(Errors)
+	assert.Equal(t, CodeParamOverflow, errs[0].Code)
+	assert.Equal(t, CodeParamInvalid, errs[2].Code)
+}
+
+func TestNumericTags(t *testing.T) {
+	obj := numericParam{}
+
+	body := `{"id": 0, "level": 6, "count": 1001, "size": 0, "tags": [1, 101]}`
+	req := request("POST", "/", body, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "id: smaller than 1; level: not in range (-5, 5); count: greater than 1000; "+
+		"size: not in range (1, 10); tags: greater than 100", err.Error())
+}
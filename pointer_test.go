@@ -0,0 +1,73 @@
+package validator
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pointerParam struct {
+	Name   *string   `form:"name" json:"name" valid:"optional" regexp:"^[a-z]+$"`
+	Age    *int      `form:"age" json:"age" valid:"optional" max:"25"`
+	Passed *bool     `form:"passed" json:"passed" valid:"optional"`
+	Score  *float64  `form:"score" json:"score" valid:"optional" default:"60"`
+	Flags  []*uint8  `form:"flags" json:"flags" valid:"optional"`
+	Tags   *[]string `form:"tags" json:"tags" valid:"optional"`
+}
+
+func TestPointerAbsent(t *testing.T) {
+	obj := pointerParam{}
+
+	req := request("POST", "/", url.Values{}.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Nil(t, obj.Name)
+	assert.Nil(t, obj.Age)
+	assert.Nil(t, obj.Passed)
+	assert.Equal(t, float64(60), *obj.Score)
+	assert.Nil(t, obj.Flags)
+	assert.Nil(t, obj.Tags)
+
+	obj = pointerParam{}
+	req = request("POST", "/", `{"age": 0}`, ContentTypeJson)
+	err = Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Nil(t, obj.Name)
+	assert.Equal(t, 0, *obj.Age)
+}
+
+func TestPointerPresent(t *testing.T) {
+	obj := pointerParam{}
+
+	body := url.Values{}
+	body.Add("name", "tony")
+	body.Add("age", "0")
+	body.Add("passed", "false")
+	body.Add("flags[]", "1")
+	body.Add("tags[]", "a")
+	body.Add("tags[]", "b")
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "tony", *obj.Name)
+	assert.Equal(t, 0, *obj.Age)
+	assert.Equal(t, false, *obj.Passed)
+	assert.Equal(t, uint8(1), *obj.Flags[0])
+	assert.Equal(t, []string{"a", "b"}, *obj.Tags)
+}
+
+func TestPointerInvalid(t *testing.T) {
+	obj := pointerParam{}
+
+	body := url.Values{}
+	body.Add("name", "Tony")
+	body.Add("age", "30")
+	body.Add("passed", "maybe")
+	body.Add("flags[]", "300")
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "name: wrong format, shold match regexp `^[a-z]+$`; age: greater than 25; "+
+		"passed: bool expected; flags: 300 overflows uint8", err.Error())
+}
@@ -0,0 +1,114 @@
+package validator
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Coercer is implemented by types that can set themselves from the string
+// value of a param. It takes precedence over encoding.TextUnmarshaler.
+type Coercer interface {
+	Coerce(param string) error
+}
+
+var (
+	typeOfCoercer         = reflect.TypeOf((*Coercer)(nil)).Elem()
+	typeOfTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+	typeCoercersMu sync.RWMutex
+	typeCoercers   = make(map[reflect.Type]func(string) (interface{}, error))
+)
+
+// RegisterTypeCoercer registers fn to coerce params to the type t, for types
+// which neither implement Coercer nor encoding.TextUnmarshaler. The value
+// returned by fn must be assignable to t. It is usually called from init:
+//
+//	validator.RegisterTypeCoercer(reflect.TypeOf(decimal.Decimal{}), func(s string) (interface{}, error) {
+//		return decimal.NewFromString(s)
+//	})
+func RegisterTypeCoercer(t reflect.Type, fn func(string) (interface{}, error)) {
+	typeCoercersMu.Lock()
+	defer typeCoercersMu.Unlock()
+	typeCoercers[t] = fn
+}
+
+func typeCoercer(t reflect.Type) func(string) (interface{}, error) {
+	typeCoercersMu.RLock()
+	defer typeCoercersMu.RUnlock()
+	return typeCoercers[t]
+}
+
+// isCoercible reports whether values of type t are set by a registered type
+// coercer, a Coercer or an encoding.TextUnmarshaler, rather than by kind.
+func isCoercible(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface {
+		return false
+	}
+	pt := reflect.PtrTo(t)
+	return typeCoercer(t) != nil || pt.Implements(typeOfCoercer) || pt.Implements(typeOfTextUnmarshaler)
+}
+
+// coerceCustom sets val from param with the custom coercion of its type.
+func coerceCustom(val reflect.Value, param string) error {
+	if fn := typeCoercer(val.Type()); fn != nil {
+		v, err := fn(param)
+		if err != nil {
+			return err
+		}
+		rv := reflect.ValueOf(v)
+		if !rv.IsValid() || !rv.Type().AssignableTo(val.Type()) {
+			return fmt.Errorf("type coercer of %s returned %T", val.Type(), v)
+		}
+		val.Set(rv)
+		return nil
+	}
+	if c, ok := val.Addr().Interface().(Coercer); ok {
+		return c.Coerce(param)
+	}
+	return val.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(param))
+}
+
+// FieldContext is the field handed to a validation registered by
+// RegisterValidation.
+type FieldContext struct {
+	// Field is the path of the field, e.g. `address.city`.
+	Field string
+	// Param is the value of the tag the validation is registered for.
+	Param string
+	// Value is the bound value of the field.
+	Value reflect.Value
+	// Tag holds every tag of the field.
+	Tag reflect.StructTag
+	// Parent is the struct holding the field.
+	Parent reflect.Value
+}
+
+var (
+	validationsMu sync.RWMutex
+	validations   = make(map[string]func(FieldContext) error)
+)
+
+// RegisterValidation registers fn to validate every field having the tag,
+// after the built-in tags passed. A non-nil error returned by fn is reported
+// as a FieldError with the tag as Code, unless it is a *FieldError or an
+// ErrorCode itself:
+//
+//	validator.RegisterValidation("sku", func(fc validator.FieldContext) error {
+//		if !skuPattern.MatchString(fc.Value.String()) {
+//			return errors.New("invalid sku")
+//		}
+//		return nil
+//	})
+func RegisterValidation(tag string, fn func(FieldContext) error) {
+	validationsMu.Lock()
+	defer validationsMu.Unlock()
+	validations[tag] = fn
+}
+
+func validation(tag string) func(FieldContext) error {
+	validationsMu.RLock()
+	defer validationsMu.RUnlock()
+	return validations[tag]
+}
@@ -0,0 +1,541 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// formats are the tags naming a field in the request.
+var formats = []string{"form", "json", "xml", "yaml", "toml"}
+
+// schemas caches the *structSchema of every struct type bound so far.
+var schemas sync.Map
+
+// structSchema is the binding plan of a struct type: which fields are bound,
+// under which names, and their parsed tags.
+type structSchema struct {
+	fields []*fieldSchema
+	// hook is set if the struct is Validatable or ContextValidatable.
+	hook bool
+	// strict is set if the struct embeds Strict.
+	strict bool
+	// anyStrict is set if the struct or one of its nested structs embeds
+	// Strict, see hasStrict.
+	anyStrict     bool
+	anyStrictOnce sync.Once
+	// tagErr reports the malformed tags of the struct and of its nested
+	// structs, see checkTags.
+	tagErr     error
+	tagErrOnce sync.Once
+}
+
+type fieldKind int
+
+const (
+	// fieldValue is bound from a single param, or a list of params for a slice
+	fieldValue fieldKind = iota
+	// fieldNested is a struct or pointer-to-struct bound field by field
+	fieldNested
+	// fieldMap is a map bound from the params nested under its name
+	fieldMap
+)
+
+type fieldSchema struct {
+	index int
+	// goName is the Go name of the field, its name in the formats it has no
+	// tag for.
+	goName string
+	// names holds the name of the field for each of the formats.
+	names map[string]string
+	// embedded is set for an anonymous struct field without name, whose
+	// fields are promoted.
+	embedded bool
+	kind     fieldKind
+	tag      reflect.StructTag
+	// tagKeys are the keys of tag, in order.
+	tagKeys []string
+	// quoted is set for a field with the `,string` json option.
+	quoted bool
+	rules  *rules
+	// comparisons are the tags comparing the field to another one.
+	comparisons []*comparison
+}
+
+// schemaOf returns the cached binding plan of the struct type t.
+func schemaOf(t reflect.Type) *structSchema {
+	if s, ok := schemas.Load(t); ok {
+		return s.(*structSchema)
+	}
+	s, _ := schemas.LoadOrStore(t, buildSchema(t))
+	return s.(*structSchema)
+}
+
+// Compile checks the tags of the struct obj points to, and of its nested
+// structs, reporting every malformed tag in Errors named after the Go field
+// path, e.g. `Address.Zip`. The checked struct types are cached, so it is
+// meant to be called from init or a unit test:
+//
+//	func init() {
+//		validator.MustCompile(&SignupParams{})
+//	}
+//
+// Binding a struct with malformed tags does not panic either, but returns
+// ErrInvalidTag, listing them as Compile does, rather than Errors.
+func Compile(obj interface{}) error {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf(ERR_COMPILE_NOT_STRUCT, obj)
+	}
+	var errs Errors
+	compileStruct(t, "", map[reflect.Type]bool{}, &errs)
+	return errs.orNil()
+}
+
+// MustCompile is like Compile but panics if a tag is malformed.
+func MustCompile(obj interface{}) {
+	if err := Compile(obj); err != nil {
+		panic(err)
+	}
+}
+
+// checkTags returns ErrInvalidTag, listing the malformed tags of the struct t
+// as Compile does, if any. Bind checks them once per type, so that they are
+// never reported as invalid params.
+func checkTags(t reflect.Type) error {
+	s := schemaOf(t)
+	s.tagErrOnce.Do(func() {
+		var errs Errors
+		compileStruct(t, "", map[reflect.Type]bool{}, &errs)
+		if len(errs) > 0 {
+			s.tagErr = fmt.Errorf("%w: %v", ErrInvalidTag, errs)
+		}
+	})
+	return s.tagErr
+}
+
+func compileStruct(t reflect.Type, path string, seen map[reflect.Type]bool, errs *Errors) {
+	if seen[t] {
+		return
+	}
+	seen[t] = true
+	for _, f := range schemaOf(t).fields {
+		fieldPath := joinPath(path, t.Field(f.index).Name)
+		for _, err := range f.rules.errs {
+			e := *err
+			errs.add(fieldPath, &e)
+		}
+		// Nested structs, and the structs of a slice or map field, are
+		// compiled too
+		ft := t.Field(f.index).Type
+		if isStructMap(ft) {
+			ft = ft.Elem()
+		}
+		if ft = elemType(ft); isNestedStruct(ft) {
+			compileStruct(ft, fieldPath, seen, errs)
+		}
+	}
+}
+
+func buildSchema(t reflect.Type) *structSchema {
+	pt := reflect.PtrTo(t)
+	s := &structSchema{
+		hook: pt.Implements(typeOfValidatable) || pt.Implements(typeOfContextValidatable),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type == typeOfStrict {
+			s.strict = true
+			continue
+		}
+		if sf.PkgPath != "" && !(sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+			continue
+		}
+
+		f := &fieldSchema{
+			index:   i,
+			goName:  sf.Name,
+			names:   make(map[string]string, len(formats)),
+			tag:     sf.Tag,
+			tagKeys: tagKeys(sf.Tag),
+			quoted:  jsonQuoted(sf),
+		}
+		for _, format := range formats {
+			f.names[format] = tagName(sf.Tag, format)
+		}
+		switch {
+		case isNestedStruct(sf.Type):
+			f.kind = fieldNested
+			f.embedded = sf.Anonymous
+		case sf.Type.Kind() == reflect.Map:
+			f.kind = fieldMap
+		}
+		if sf.PkgPath != "" && !f.embedded {
+			continue
+		}
+		f.rules = parseRules(sf.Tag, sf.Type)
+		if f.kind == fieldValue {
+			f.comparisons = parseComparisons(t, sf, f.rules)
+		}
+		// Slices and maps of structs are not bound from form
+		if name := f.names["form"]; name != "" && name != "-" &&
+			(f.kind == fieldValue && isNestedStruct(elemType(sf.Type)) || isStructMap(sf.Type)) {
+			f.rules.notApplicable("form", name, sf.Type)
+		}
+		s.fields = append(s.fields, f)
+	}
+	resolveConditions(t, s)
+	return s
+}
+
+// name returns the name of f in format, and whether f is bound in format
+// at all. The fields of an embedded struct are promoted when it has no name.
+func (f *fieldSchema) name(format string) (string, bool) {
+	name := f.names[format]
+	return name, name != "-"
+}
+
+// path returns the path of f nested under path in format, named after the Go
+// field when it has no name in format, as the decoders do.
+func (f *fieldSchema) path(path string, format string) string {
+	name := f.names[format]
+	if name == "" {
+		if f.embedded {
+			return path
+		}
+		name = f.goName
+	}
+	return joinPath(path, name)
+}
+
+// rules are the built-in tags of a field, parsed once.
+type rules struct {
+	valid        string
+	defaultValue string
+	typ          string
+	timeFormat   string
+
+	// transforms are applied in order to a string before it is validated.
+	transforms []func(string) string
+	values     *valueSet
+	notValues  *valueSet
+	// allowEmpty accepts an empty string, trimBlank rejects a whitespace-only one.
+	allowEmpty  bool
+	trimBlank   bool
+	regexp      *regexp.Regexp
+	formats     []stringFormat
+	formatParam string
+	minLen      *bound
+	maxLen      *bound
+	length      *bound
+	lenUnit     string
+	keys        []string
+	maxSize     *bound
+	minItems    *bound
+	maxItems    *bound
+	unique      bool
+	min         *bound
+	max         *bound
+	rng         [2]*bound
+	// elem are the rules of the elements of a slice or the values of a map
+	// given by the `dive` tag, they are r itself without it.
+	elem *rules
+
+	// condition is set for a conditional `valid` tag, valid is then its kind.
+	condition *condition
+
+	// errs are the malformed tags, the first one is reported whenever the
+	// field is bound. Compile reports all of them.
+	errs []*FieldError
+}
+
+// noRules are the rules of a value without tags, such as a map key.
+var noRules = &rules{}
+
+// bound is the parsed value of a `min`, `max` or one side of a `range` tag,
+// for the type of the field.
+type bound struct {
+	param string
+	i     int64
+	u     uint64
+	f     float64
+	d     time.Duration
+	t     time.Time
+	// now is set for a time bound relative to the time of the request,
+	// offset by d.
+	now bool
+}
+
+// time returns the time of a bound of a time.Time field.
+func (b *bound) time() time.Time {
+	if b.now {
+		return time.Now().Add(b.d)
+	}
+	return b.t
+}
+
+// parseRules parses the built-in tags of a field of type t.
+func parseRules(tag reflect.StructTag, t reflect.Type) *rules {
+	r := &rules{
+		valid:        tag.Get("valid"),
+		defaultValue: tag.Get("default"),
+		typ:          tag.Get("type"),
+		timeFormat:   tag.Get("time_format"),
+	}
+
+	// Tags of a map apply to its values, of a slice to its elements, unless
+	// they are given by the `dive` tag
+	bt := t
+	if bt.Kind() == reflect.Ptr {
+		bt = bt.Elem()
+	}
+	vt := bt
+	if vt.Kind() == reflect.Map {
+		vt = vt.Elem()
+	}
+	vt = elemType(vt)
+
+	valid := r.valid
+	kind, param := valid, ""
+	if i := strings.Index(valid, "="); i >= 0 {
+		kind, param = valid[:i], valid[i+1:]
+	}
+	switch kind {
+	case "required", "optional":
+		if len(param) == 0 {
+			break
+		}
+		fallthrough
+	default:
+		if valid != "" {
+			r.fail(&FieldError{Tag: "valid", Param: valid, Code: CodeInvalidTag, Message: ERR_INVALID_VALID_TAG})
+		}
+	case "required_if", "required_unless", "required_with", "required_without":
+		if c, ok := parseCondition(valid, kind, param); ok {
+			r.valid, r.condition = kind, c
+		} else {
+			r.fail(&FieldError{Tag: "valid", Param: valid, Code: CodeInvalidTag, Message: ERR_INVALID_VALID_TAG})
+		}
+	}
+	switch r.typ {
+	case "":
+	case "file", "base64":
+		if bt.Kind() != reflect.Slice || bt.Elem().Kind() != reflect.Uint8 {
+			r.fail(&FieldError{Tag: "type", Param: r.typ, Code: CodeInvalidTag, Message: ERR_TYPE_WITHOUT_BYTES})
+		}
+	default:
+		r.fail(&FieldError{Tag: "type", Param: r.typ, Code: CodeInvalidTag, Message: ERR_INVALID_TYPE_TAG})
+	}
+	if len(r.timeFormat) != 0 && vt != typeOfTime {
+		r.notApplicable("time_format", r.timeFormat, t)
+	}
+	vt = r.parseItems(tag, t, bt, vt)
+
+	r.values = r.parseValueSet(tag, "values", t, vt)
+	r.notValues = r.parseValueSet(tag, "not_values", t, vt)
+	if param := tag.Get("keys"); len(param) != 0 {
+		if bt.Kind() != reflect.Map {
+			r.notApplicable("keys", param, t)
+		}
+		r.keys = strings.Split(param, "|")
+	}
+	if param := tag.Get("regexp"); len(param) != 0 {
+		if vt.Kind() != reflect.String {
+			r.notApplicable("regexp", param, t)
+		} else if re, err := regexp.Compile(param); err != nil {
+			r.fail(&FieldError{Tag: "regexp", Param: param, Code: CodeInvalidTag, Message: ERR_INVALID_REGEXP_TAG})
+		} else {
+			r.regexp = re
+		}
+	}
+	r.parseTransforms(tag, t, vt)
+	r.parseBlank(tag, t, vt)
+	r.parseLengths(tag, t, vt)
+	r.parseFormats(tag, t, vt)
+	if param := tag.Get("max_size"); len(param) != 0 {
+		if r.typ != "file" {
+			r.fail(&FieldError{Tag: "max_size", Param: param, Code: CodeInvalidTag, Message: ERR_MAX_SIZE_WITHOUT_FILE})
+		} else {
+			r.maxSize = r.parseInt("max_size", param, ERR_INVALID_MAX_SIZE_TAG)
+		}
+	}
+
+	if param := tag.Get("max"); len(param) != 0 {
+		r.max = r.parseBound("max", param, param, vt)
+	}
+	if param := tag.Get("min"); len(param) != 0 {
+		r.min = r.parseBound("min", param, param, vt)
+	}
+	if param := tag.Get("range"); len(param) != 0 {
+		bounds := strings.Split(param, "|")
+		if len(bounds) != 2 {
+			bounds = []string{param, ""}
+		}
+		if r.rng[0] = r.parseBound("range", param, bounds[0], vt); r.rng[0] != nil {
+			r.rng[1] = r.parseBound("range", param, bounds[1], vt)
+		}
+	}
+
+	if len(r.defaultValue) != 0 {
+		r.parseDefault(t)
+	}
+	return r
+}
+
+// parseBlank parses the `allow_empty` and `nonblank` tags of a string field.
+// An empty string is rejected unless `allow_empty:"true"`, and so is a
+// whitespace-only one with `nonblank:"trim"`.
+func (r *rules) parseBlank(tag reflect.StructTag, t, vt reflect.Type) {
+	allowEmpty, nonblank := tag.Get("allow_empty"), tag.Get("nonblank")
+	if len(allowEmpty) == 0 && len(nonblank) == 0 {
+		return
+	}
+	if vt.Kind() != reflect.String {
+		if len(allowEmpty) != 0 {
+			r.notApplicable("allow_empty", allowEmpty, t)
+		}
+		if len(nonblank) != 0 {
+			r.notApplicable("nonblank", nonblank, t)
+		}
+		return
+	}
+
+	if len(allowEmpty) != 0 {
+		allow, err := strconv.ParseBool(allowEmpty)
+		if err != nil {
+			r.fail(&FieldError{Tag: "allow_empty", Param: allowEmpty, Code: CodeInvalidTag,
+				Message: ERR_INVALID_ALLOW_EMPTY_TAG})
+		}
+		r.allowEmpty = allow
+	}
+	switch nonblank {
+	case "", "true":
+	case "trim":
+		r.trimBlank = true
+	default:
+		r.fail(&FieldError{Tag: "nonblank", Param: nonblank, Code: CodeInvalidTag, Message: ERR_INVALID_NONBLANK_TAG})
+	}
+	if r.allowEmpty && len(nonblank) != 0 {
+		r.fail(&FieldError{Tag: "nonblank", Param: nonblank, Code: CodeInvalidTag, Message: ERR_NONBLANK_WITH_ALLOW_EMPTY})
+	}
+}
+
+// parseDefault checks that the `default` tag is allowed and can be coerced
+// to t, so that a malformed default is not only found once the param is absent.
+func (r *rules) parseDefault(t reflect.Type) {
+	if r.valid != "optional" {
+		r.fail(&FieldError{Tag: "default", Param: r.defaultValue, Code: CodeInvalidTag,
+			Message: ERR_DEFAULT_WITHOUT_OPTIONAL})
+		return
+	}
+	if isNestedStruct(t) || t.Kind() == reflect.Map || r.typ == "file" {
+		r.notApplicable("default", r.defaultValue, t)
+		return
+	}
+	if r.typ != "" || len(r.errs) != 0 {
+		return
+	}
+	val := reflect.New(t).Elem()
+	if val.Kind() == reflect.Ptr {
+		val.Set(reflect.New(t.Elem()))
+		val = val.Elem()
+	}
+	if err := setParams(val, []string{r.defaultValue}, r); err != nil {
+		r.fail(&FieldError{Tag: "default", Param: r.defaultValue, Code: CodeInvalidTag,
+			Message: fmt.Sprintf(ERR_INVALID_DEFAULT_TAG, err.Message)})
+	}
+}
+
+// fail records err, a malformed tag.
+func (r *rules) fail(err *FieldError) {
+	r.errs = append(r.errs, err)
+}
+
+// notApplicable records that the tag name can not be used on a field of type t.
+func (r *rules) notApplicable(name, param string, t reflect.Type) {
+	r.fail(&FieldError{Tag: name, Param: param, Code: CodeInvalidTag,
+		Message: fmt.Sprintf(ERR_TAG_NOT_APPLICABLE, name, t.String())})
+}
+
+// tagError returns a copy of the first malformed tag, if any.
+func (r *rules) tagError() *FieldError {
+	if len(r.errs) == 0 {
+		return nil
+	}
+	e := *r.errs[0]
+	return &e
+}
+
+func (r *rules) parseInt(name, param string, msg string) *bound {
+	i, err := strconv.ParseInt(param, 10, 64)
+	if err != nil || i < 0 {
+		r.fail(&FieldError{Tag: name, Param: param, Code: CodeInvalidTag, Message: msg})
+		return nil
+	}
+	return &bound{param: param, i: i}
+}
+
+// Messages of a malformed `max`, `min` or `range` tag, by type of the field
+var (
+	numberBoundMessages = map[string]string{
+		"max": ERR_INVALID_MAX_TAG, "min": ERR_INVALID_MIN_TAG, "range": ERR_INVALID_RANGE_TAG,
+	}
+	timeBoundMessages = map[string]string{
+		"max": ERR_INVALID_TIME_MAX_TAG, "min": ERR_INVALID_TIME_MIN_TAG, "range": ERR_INVALID_TIME_RANGE_TAG,
+	}
+	durationBoundMessages = map[string]string{
+		"max": ERR_INVALID_DURATION_MAX_TAG, "min": ERR_INVALID_DURATION_MIN_TAG, "range": ERR_INVALID_DURATION_RANGE_TAG,
+	}
+)
+
+// parseBound parses s, the tag name or one side of it, for values of type t.
+// Tags on types without bounds are reported as malformed.
+func (r *rules) parseBound(name, param, s string, t reflect.Type) *bound {
+	b := &bound{param: s}
+	var err error
+	var msg string
+	switch {
+	case t == typeOfTime:
+		msg = timeBoundMessages[name]
+		if strings.HasPrefix(s, "now") {
+			b.now = true
+			if s != "now" {
+				b.d, err = time.ParseDuration(s[len("now"):])
+			}
+		} else {
+			b.t, err = parseTime(s, r.timeFormat)
+		}
+	case t == typeOfDuration:
+		msg = durationBoundMessages[name]
+		b.d, err = time.ParseDuration(s)
+	case isCoercible(t):
+		r.notApplicable(name, param, t)
+		return nil
+	default:
+		msg = numberBoundMessages[name]
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			b.i, err = strconv.ParseInt(s, 10, 64)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			b.u, err = strconv.ParseUint(s, 10, 64)
+		case reflect.Float32, reflect.Float64:
+			b.f, err = strconv.ParseFloat(s, 64)
+		default:
+			r.notApplicable(name, param, t)
+			return nil
+		}
+	}
+	if err != nil {
+		r.fail(&FieldError{Tag: name, Param: param, Code: CodeInvalidTag, Message: msg})
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,129 @@
+package validator
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaCached(t *testing.T) {
+	typ := reflect.TypeOf(regexpTagParam{})
+	s := schemaOf(typ)
+	assert.True(t, s == schemaOf(typ))
+	assert.Len(t, s.fields, 1)
+	assert.Equal(t, "^[a-zA-Z_][a-zA-Z_]*$", s.fields[0].rules.regexp.String())
+	assert.Equal(t, "name", s.fields[0].names["form"])
+}
+
+type malformedTagParam struct {
+	Name  string `form:"name" valid:"required" regexp:"[a-"`
+	Age   int    `form:"age" valid:"required" range:"18"`
+	Score int    `form:"score" valid:"required" max:"ten"`
+}
+
+func TestMalformedTagReported(t *testing.T) {
+	obj := malformedTagParam{}
+
+	body := url.Values{}
+	body.Add("name", "Tony")
+	body.Add("age", "20")
+	body.Add("score", "5")
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "invalid tag: Name: invalid `regexp` tag, must be a valid regular expression; "+
+		"Age: invalid `range` tag, must be (int|int) or (float|float); "+
+		"Score: invalid `max` tag, must be int or float", err.Error())
+	// Not an invalid request, but a malformed struct
+	assert.True(t, errors.Is(err, ErrInvalidTag))
+	var errs Errors
+	assert.False(t, errors.As(err, &errs))
+
+	req = request("POST", "/", `{"name": "Tony"}`, ContentTypeJson)
+	assert.True(t, errors.Is(Bind(req, &obj), ErrInvalidTag))
+}
+
+func TestCompile(t *testing.T) {
+	for _, obj := range []interface{}{
+		&benchParam{}, &customTypeParam{}, &multiErrorParam{}, &FormParam{}, &periodParam{},
+		&contactParam{}, &JsonParam{}, &mapParam{}, &MultipartFormParam{}, &nestedParam{},
+		&numericParam{}, &pointerParam{}, &timeParam{}, &defaultParam{}, &optionalParam{},
+		&optionalWithDefaultParam{}, &regexpTagParam{}, &valuesTagParam{}, &fileMaxSizeTagParam{},
+		&minTagIntParam{}, &maxTagIntParam{}, &rangeTagIntParam{}, &customRuleParam{},
+	} {
+		assert.NoError(t, Compile(obj), "%T", obj)
+	}
+}
+
+type malformedNestedParam struct {
+	Zip   string   `form:"zip" valid:"required" min:"5"`
+	Lines []string `form:"lines" valid:"maybe"`
+}
+
+type compileParam struct {
+	Name    string               `form:"name" valid:"required" regexp:"[a-"`
+	Age     int                  `form:"age" valid:"required" min:"eighteen" range:"18"`
+	Country string               `form:"country" default:"fr"`
+	Level   int                  `form:"level" valid:"optional" default:"high"`
+	Avatar  []byte               `form:"avatar" valid:"optional" max_size:"1024"`
+	Picture string               `form:"picture" valid:"optional" type:"file"`
+	Address malformedNestedParam `form:"address" valid:"required"`
+	// Reported once, under Address
+	Previous *malformedNestedParam `form:"previous" valid:"optional"`
+}
+
+func TestCompileMalformedTags(t *testing.T) {
+	err := Compile(&compileParam{})
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, CodeInvalidTag))
+
+	errs := err.(Errors)
+	fields := make([]string, len(errs))
+	for i, e := range errs {
+		fields[i] = e.Field + " " + e.Tag
+	}
+	assert.Equal(t, []string{
+		"Name regexp",
+		"Age min",
+		"Age range",
+		"Country default",
+		"Level default",
+		"Avatar max_size",
+		"Picture type",
+		"Address.Zip min",
+		"Address.Lines valid",
+	}, fields)
+	assert.Equal(t, "invalid `min` tag, not allowed on string", errs[7].Message)
+	assert.Equal(t, "invalid `default` tag, only allowed with `valid:\"optional\"`", errs[3].Message)
+	assert.Equal(t, "invalid `default` tag, int expected", errs[4].Message)
+
+	assert.Panics(t, func() { MustCompile(&compileParam{}) })
+	assert.NotPanics(t, func() { MustCompile(regexpTagParam{}) })
+	assert.Error(t, Compile("name"))
+}
+
+type recursiveParam struct {
+	Name string          `form:"name" valid:"required" max:"10"`
+	Next *recursiveParam `form:"next" valid:"optional"`
+}
+
+func TestCompileRecursive(t *testing.T) {
+	err := Compile(&recursiveParam{})
+	assert.Error(t, err)
+	assert.Len(t, err.(Errors), 1)
+}
+
+func TestMalformedTagDoesNotPanic(t *testing.T) {
+	obj := compileParam{}
+	body := url.Values{}
+	body.Add("name", "Tony")
+	body.Add("picture", "me.png")
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	assert.NotPanics(t, func() {
+		err := Bind(req, &obj)
+		assert.True(t, errors.Is(err, ErrInvalidTag))
+	})
+}
@@ -0,0 +1,240 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Strict is embedded in a struct to bind it in strict mode. The json body
+// of the request is then rejected if it has keys which do not map to a field,
+// duplicate keys, or data after the json value, and so are form, multipart
+// and query keys which do not map to a field, e.g. `?agee=20`. Every such key
+// is reported in Errors by path, e.g. `address.zipcode: unknown field`.
+// Strict mode applies to the structs nested in a strict struct as well:
+//
+//	type signup struct {
+//		validator.Strict
+//		Name string `json:"name" valid:"required"`
+//	}
+type Strict struct{}
+
+var typeOfStrict = reflect.TypeOf(Strict{})
+
+// checkDuplicateKeys reports the keys found twice in an object of the json
+// value data at path.
+func checkDuplicateKeys(data []byte, path string, errs *Errors) {
+	scanJsonValue(json.NewDecoder(bytes.NewReader(data)), path, errs)
+}
+
+func scanJsonValue(dec *json.Decoder, path string, errs *Errors) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		seen := make(map[string]bool)
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := tok.(string)
+			if seen[key] {
+				errs.add(joinPath(path, key), &FieldError{Code: CodeDuplicateKey, Message: ERR_DUPLICATE_KEY})
+			}
+			seen[key] = true
+			if err := scanJsonValue(dec, joinPath(path, key), errs); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if err := scanJsonValue(dec, joinPath(path, strconv.Itoa(i)), errs); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+	// Closing delimiter
+	_, err = dec.Token()
+	return err
+}
+
+// checkUnknownKeys reports the keys of a json object which do not map to a
+// field of the struct type t, matching names case-insensitively as
+// encoding/json does.
+func checkUnknownKeys(fields map[string]json.RawMessage, t reflect.Type, path string, errs *Errors) {
+	known := make(map[string]bool)
+	jsonNames(t, known)
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !known[strings.ToLower(key)] {
+			errs.add(joinPath(path, key), &FieldError{Code: CodeUnknownField, Message: ERR_UNKNOWN_FIELD})
+		}
+	}
+}
+
+// jsonNames adds the lower-cased json names of the fields of the struct type
+// t to names, including the fields promoted from embedded structs.
+func jsonNames(t reflect.Type, names map[string]bool) {
+	for _, f := range schemaOf(t).fields {
+		name, ok := f.name("json")
+		if !ok {
+			continue
+		}
+		if f.embedded && name == "" {
+			ft := t.Field(f.index).Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			jsonNames(ft, names)
+			continue
+		}
+		if name == "" {
+			name = t.Field(f.index).Name
+		}
+		names[strings.ToLower(name)] = true
+	}
+}
+
+// unknownFieldError returns the key of the error returned by a json.Decoder
+// disallowing unknown fields, or "" for another error.
+func unknownFieldError(err error) string {
+	const prefix = `json: unknown field "`
+	if msg := err.Error(); strings.HasPrefix(msg, prefix) {
+		return strings.TrimSuffix(msg[len(prefix):], `"`)
+	}
+	return ""
+}
+
+// checkUnknownParams reports the form, multipart and query keys which do not
+// map to a field of the struct type t, if they are under a strict struct.
+func checkUnknownParams(t reflect.Type, formData map[string][]string,
+	formFile map[string][]*multipart.FileHeader, errs *Errors) {
+	if !schemaOf(t).hasStrict(t) {
+		return
+	}
+	keys := sortedKeys(formData)
+	for key := range formFile {
+		if _, ok := formData[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		names, ok := splitParam(key)
+		if known, strict := lookupParam(t, names); ok && known || !strict {
+			continue
+		}
+		errs.add(key, &FieldError{Code: CodeUnknownField, Message: ERR_UNKNOWN_FIELD})
+	}
+}
+
+// hasStrict reports whether s, the schema of the struct type t, or the schema
+// of one of its nested structs is strict. It is computed on first use rather
+// than by buildSchema, as nested structs may be recursive.
+func (s *structSchema) hasStrict(t reflect.Type) bool {
+	s.anyStrictOnce.Do(func() {
+		s.anyStrict = containsStrict(t, map[reflect.Type]bool{})
+	})
+	return s.anyStrict
+}
+
+func containsStrict(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	s := schemaOf(t)
+	if s.strict {
+		return true
+	}
+	for _, f := range s.fields {
+		if f.kind != fieldNested {
+			continue
+		}
+		ft := t.Field(f.index).Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if containsStrict(ft, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitParam splits a form key in dotted (`address.city`) or bracketed
+// (`address[city]`) syntax into names, dropping a trailing `[]`. It reports
+// false for a malformed bracketed key, which is never bound.
+func splitParam(key string) ([]string, bool) {
+	key = strings.TrimSuffix(key, "[]")
+	i := strings.Index(key, "[")
+	if i < 0 {
+		return strings.Split(key, "."), true
+	}
+	names := []string{key[:i]}
+	for rest := key[i:]; rest != ""; {
+		j := strings.Index(rest, "]")
+		if rest[0] != '[' || j < 0 {
+			return names, false
+		}
+		names = append(names, rest[1:j])
+		rest = rest[j+1:]
+	}
+	return names, true
+}
+
+// lookupParam reports whether the form key split into names maps to a field
+// of the struct type t. Otherwise it reports whether the key is under a strict
+// struct, that is t or the nested struct matching the longest prefix of names.
+func lookupParam(t reflect.Type, names []string) (known, strict bool) {
+	s := schemaOf(t)
+	for _, f := range s.fields {
+		name, ok := f.name("form")
+		if !ok {
+			continue
+		}
+		ft := t.Field(f.index).Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.embedded && name == "" {
+			if known, _ := lookupParam(ft, names); known {
+				return true, false
+			}
+			continue
+		}
+		if name != names[0] {
+			continue
+		}
+		switch f.kind {
+		case fieldNested:
+			if len(names) > 1 {
+				known, strict := lookupParam(ft, names[1:])
+				return known, s.strict || strict
+			}
+		case fieldMap:
+			// As accepted by formPath.entries
+			if len(names) == 2 && names[1] != "" && !strings.ContainsAny(names[1], ".[]") {
+				return true, false
+			}
+		default:
+			if len(names) == 1 {
+				return true, false
+			}
+		}
+	}
+	return false, s.strict
+}
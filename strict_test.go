@@ -0,0 +1,161 @@
+package validator
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type strictItem struct {
+	Sku string `json:"sku" valid:"required"`
+}
+
+type strictAddress struct {
+	City string `form:"city" json:"city" valid:"required"`
+}
+
+type strictParam struct {
+	Strict
+	Name    string            `form:"name" json:"name" valid:"required"`
+	Secret  string            `form:"-" json:"-" valid:"optional"`
+	Address strictAddress     `form:"address" json:"address"`
+	Items   []strictItem      `form:"-" json:"items" valid:"optional"`
+	Tags    []string          `form:"tags" json:"tags" valid:"optional"`
+	Meta    map[string]string `form:"meta" json:"meta" valid:"optional"`
+}
+
+func TestStrictJson(t *testing.T) {
+	obj := strictParam{}
+	req := request("POST", "/", `{"Name": "bob", "address": {"city": "Paris"}, "items": [{"sku": "a"}]}`,
+		ContentTypeJson)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "bob", obj.Name)
+	assert.Equal(t, "Paris", obj.Address.City)
+
+	obj = strictParam{}
+	req = request("POST", "/", `{"name": "bob", "age": 3, "Secret": "x", "address": {"city": "Paris", "zip": "1"}}`,
+		ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "address.zip: unknown field; Secret: unknown field; age: unknown field", err.Error())
+	errs := err.(Errors)
+	assert.Equal(t, CodeUnknownField, errs[0].Code)
+
+	req = request("POST", "/", `{"name": "bob", "address": {"city": "Paris"}, "items": [{"sku": "a", "qty": 1}]}`,
+		ContentTypeJson)
+	err = Bind(req, &strictParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "items.0.qty: unknown field", err.Error())
+
+	req = request("POST", "/", `{"name": "bob", "address": {"city": "Paris"},
+		"items": [{"sku": "a", "bad": 1}, {"sku": "b"}, {"sku": "c", "bad2": 2, "bad3": 3}]}`, ContentTypeJson)
+	err = Bind(req, &strictParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "items.0.bad: unknown field; items.2.bad2: unknown field; items.2.bad3: unknown field",
+		err.Error())
+}
+
+func TestStrictJsonDuplicateKeys(t *testing.T) {
+	req := request("POST", "/", `{"name": "bob", "name": "alice", "address": {"city": "Paris"},
+		"items": [{"sku": "a"}, {"sku": "b", "sku": "c"}]}`, ContentTypeJson)
+	err := Bind(req, &strictParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "name: duplicate key; items.1.sku: duplicate key", err.Error())
+	assert.Equal(t, CodeDuplicateKey, err.(Errors)[0].Code)
+}
+
+func TestStrictJsonTrailingData(t *testing.T) {
+	req := request("POST", "/", `{"name": "bob", "address": {"city": "Paris"}} {}`, ContentTypeJson)
+	err := Bind(req, &strictParam{})
+	assert.Error(t, err)
+	assert.Equal(t, CodeTrailingData, err.(Errors)[0].Code)
+
+	req = request("POST", "/", `{"name": "bob", "address": {"city": "Paris"}}`+"\n", ContentTypeJson)
+	assert.NoError(t, Bind(req, &strictParam{}))
+}
+
+func TestNotStrictJson(t *testing.T) {
+	type param struct {
+		Name string `json:"name" valid:"required"`
+	}
+	obj := param{}
+	req := request("POST", "/", `{"name": "bob", "name": "alice", "age": 3} garbage`, ContentTypeJson)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "alice", obj.Name)
+}
+
+func TestStrictForm(t *testing.T) {
+	body := url.Values{}
+	body.Add("name", "bob")
+	body.Add("address[city]", "Paris")
+	body.Add("tags[]", "go")
+	body.Add("meta.color", "red")
+
+	obj := strictParam{}
+	req := request("POST", "/?tags[]=json", body.Encode(), ContentTypeForm)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "Paris", obj.Address.City)
+	assert.Equal(t, []string{"go", "json"}, obj.Tags)
+
+	body.Add("Secret", "x")
+	body.Add("address.zip", "1")
+	body.Add("address", "x")
+	body.Add("meta[a][b]", "x")
+	body.Add("name]", "x")
+	req = request("POST", "/?agee=20", body.Encode(), ContentTypeForm)
+	err := Bind(req, &strictParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "Secret: unknown field; address: unknown field; address.zip: unknown field; "+
+		"agee: unknown field; meta[a][b]: unknown field; name]: unknown field", err.Error())
+	assert.Equal(t, CodeUnknownField, err.(Errors)[0].Code)
+
+	req = request("GET", "/?name=bob&address.city=Paris&agee=20", "", "")
+	err = Bind(req, &strictParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "agee: unknown field", err.Error())
+}
+
+func TestStrictFormNested(t *testing.T) {
+	type address struct {
+		Strict
+		City string `form:"city" valid:"required"`
+	}
+	type param struct {
+		Name    string  `form:"name" valid:"required"`
+		Address address `form:"address"`
+	}
+	req := request("GET", "/?name=bob&address.city=Paris&address.zip=1&agee=20", "", "")
+	err := Bind(req, &param{})
+	assert.Error(t, err)
+	assert.Equal(t, "address.zip: unknown field", err.Error())
+}
+
+func TestStrictSchema(t *testing.T) {
+	type address struct {
+		Strict
+		City string `form:"city"`
+	}
+	type param struct {
+		Billing *address `form:"billing"`
+	}
+	assert.True(t, schemaOf(reflect.TypeOf(strictParam{})).hasStrict(reflect.TypeOf(strictParam{})))
+	assert.True(t, schemaOf(reflect.TypeOf(param{})).hasStrict(reflect.TypeOf(param{})))
+	assert.False(t, schemaOf(reflect.TypeOf(nestedParam{})).hasStrict(reflect.TypeOf(nestedParam{})))
+	assert.False(t, schemaOf(reflect.TypeOf(nodeParam{})).hasStrict(reflect.TypeOf(nodeParam{})))
+}
+
+func TestStrictMultipart(t *testing.T) {
+	type param struct {
+		Strict
+		Image []byte `form:"image" valid:"required" type:"file"`
+		Name  string `form:"name" valid:"required"`
+	}
+	params := map[string]string{"name": "Tony"}
+	files := map[string]string{"image": "testdata/Go-Logo_Aqua.jpg", "avatar": "testdata/Go-Logo_Blue.jpg"}
+	req := requestMultipartForm("/", params, files)
+	err := Bind(req, &param{})
+	assert.Error(t, err)
+	assert.Equal(t, "avatar: unknown field", err.Error())
+}
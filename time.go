@@ -0,0 +1,75 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	typeOfTime     = reflect.TypeOf(time.Time{})
+	typeOfDuration = reflect.TypeOf(time.Duration(0))
+)
+
+// parseTime parses param according to the `time_format` tag, which is a
+// layout of the time package, `unix`, `unixmilli`, or RFC3339 if empty.
+func parseTime(param string, format string) (time.Time, error) {
+	switch format {
+	case "":
+		return time.Parse(time.RFC3339, param)
+	case "unix", "unixmilli":
+		i, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if format == "unixmilli" {
+			return time.Unix(i/1e3, (i%1e3)*int64(time.Millisecond)), nil
+		}
+		return time.Unix(i, 0), nil
+	default:
+		return time.Parse(format, param)
+	}
+}
+
+// invalidTime reports that param is not a time in the `time_format` of r.
+func invalidTime(param string, r *rules) *FieldError {
+	format := r.timeFormat
+	if format == "" {
+		format = "RFC3339"
+	}
+	return &FieldError{Tag: "time_format", Param: r.timeFormat, Value: param, Code: CodeParamInvalid,
+		Message: fmt.Sprintf(ERR_INVALID_TIME, format)}
+}
+
+func validateTime(t time.Time, r *rules) *FieldError {
+	if r.max != nil && t.After(r.max.time()) {
+		return &FieldError{Tag: "max", Param: r.max.param, Value: t, Code: CodeGreaterThanMax,
+			Message: fmt.Sprintf(ERR_LATER_THAN_MAX, r.max.param)}
+	}
+	if r.min != nil && t.Before(r.min.time()) {
+		return &FieldError{Tag: "min", Param: r.min.param, Value: t, Code: CodeSmallerThanMin,
+			Message: fmt.Sprintf(ERR_EARLIER_THAN_MIN, r.min.param)}
+	}
+	if r.rng[0] != nil && r.rng[1] != nil && (t.Before(r.rng[0].time()) || t.After(r.rng[1].time())) {
+		return &FieldError{Tag: "range", Param: r.rng[0].param + "|" + r.rng[1].param, Value: t,
+			Code: CodeNotInRange, Message: fmt.Sprintf(ERR_NOT_IN_RANGE, r.rng[0].param, r.rng[1].param)}
+	}
+	return nil
+}
+
+func validateDuration(d time.Duration, r *rules) *FieldError {
+	if r.max != nil && d > r.max.d {
+		return &FieldError{Tag: "max", Param: r.max.param, Value: d, Code: CodeGreaterThanMax,
+			Message: fmt.Sprintf(ERR_GREATER_THAN_MAX, r.max.param)}
+	}
+	if r.min != nil && d < r.min.d {
+		return &FieldError{Tag: "min", Param: r.min.param, Value: d, Code: CodeSmallerThanMin,
+			Message: fmt.Sprintf(ERR_SMALLER_THAN_MIN, r.min.param)}
+	}
+	if r.rng[0] != nil && r.rng[1] != nil && (d < r.rng[0].d || d > r.rng[1].d) {
+		return &FieldError{Tag: "range", Param: r.rng[0].param + "|" + r.rng[1].param, Value: d,
+			Code: CodeNotInRange, Message: fmt.Sprintf(ERR_NOT_IN_RANGE, r.rng[0].param, r.rng[1].param)}
+	}
+	return nil
+}
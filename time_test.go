@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type timeParam struct {
+	CreatedAt time.Time     `form:"created_at" json:"created_at" valid:"required"`
+	Birthday  time.Time     `form:"birthday" json:"birthday" valid:"optional" time_format:"2006-01-02" range:"1900-01-01|now"`
+	ExpiresAt *time.Time    `form:"expires_at" json:"expires_at" valid:"optional" time_format:"unix" min:"now"`
+	SentAt    time.Time     `form:"sent_at" json:"sent_at" valid:"optional" time_format:"unixmilli"`
+	Timeout   time.Duration `form:"timeout" json:"timeout" valid:"optional" range:"1s|1m"`
+	Days      []time.Time   `form:"days" json:"days" valid:"optional" time_format:"2006-01-02"`
+}
+
+func TestTimeForm(t *testing.T) {
+	obj := timeParam{}
+	expires := time.Now().Add(time.Hour).Unix()
+
+	body := url.Values{}
+	body.Add("created_at", "2020-05-01T10:00:00Z")
+	body.Add("birthday", "1990-12-31")
+	body.Add("expires_at", strconv.FormatInt(expires, 10))
+	body.Add("sent_at", "1588327200123")
+	body.Add("timeout", "30s")
+	body.Add("days[]", "2020-05-01")
+	body.Add("days[]", "2020-05-02")
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC).Equal(obj.CreatedAt))
+	assert.True(t, time.Date(1990, 12, 31, 0, 0, 0, 0, time.UTC).Equal(obj.Birthday))
+	assert.Equal(t, expires, obj.ExpiresAt.Unix())
+	assert.True(t, time.Unix(1588327200, 123*int64(time.Millisecond)).Equal(obj.SentAt))
+	assert.Equal(t, 30*time.Second, obj.Timeout)
+	assert.Len(t, obj.Days, 2)
+}
+
+func TestTimeFormErrors(t *testing.T) {
+	obj := timeParam{}
+
+	body := url.Values{}
+	body.Add("created_at", "2020-05-01")
+	body.Add("birthday", "1800-01-01")
+	body.Add("expires_at", "1000")
+	body.Add("timeout", "2m")
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "created_at: time expected in format `RFC3339`; birthday: not in range (1900-01-01, now); "+
+		"expires_at: earlier than now; timeout: not in range (1s, 1m)", err.Error())
+
+	body = url.Values{}
+	body.Add("created_at", "2020-05-01T10:00:00Z")
+	body.Add("timeout", "soon")
+	req = request("POST", "/", body.Encode(), ContentTypeForm)
+	err = Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "timeout: duration expected", err.Error())
+}
+
+func TestTimeJson(t *testing.T) {
+	obj := timeParam{}
+
+	body := `{"created_at": "2020-05-01T10:00:00Z", "birthday": "1990-12-31", "sent_at": 1588327200123,
+		"timeout": "30s", "expires_at": null}`
+	req := request("POST", "/", body, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.True(t, time.Date(1990, 12, 31, 0, 0, 0, 0, time.UTC).Equal(obj.Birthday))
+	assert.True(t, time.Unix(1588327200, 123*int64(time.Millisecond)).Equal(obj.SentAt))
+	assert.Equal(t, 30*time.Second, obj.Timeout)
+	assert.Nil(t, obj.ExpiresAt)
+
+	obj = timeParam{}
+	body = `{"created_at": "2020-05-01T10:00:00Z", "birthday": "31/12/1990", "timeout": 2000000000}`
+	req = request("POST", "/", body, ContentTypeJson)
+	err = Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "birthday: time expected in format `2006-01-02`", err.Error())
+
+	obj = timeParam{}
+	body = `{"created_at": "2020-05-01T10:00:00Z", "birthday": "1990-12-31", "timeout": 2000000000}`
+	req = request("POST", "/", body, ContentTypeJson)
+	err = Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Second, obj.Timeout)
+}
+
+func TestTimeJsonItems(t *testing.T) {
+	type param struct {
+		Dates     []time.Time              `json:"dates" valid:"optional" time_format:"2006-01-02"`
+		Stamps    *[]*time.Time            `json:"stamps" valid:"optional" time_format:"unix"`
+		Holidays  map[string]time.Time     `json:"holidays" valid:"optional" time_format:"2006-01-02"`
+		Intervals []time.Duration          `json:"intervals" valid:"optional" max:"3h"`
+		Timeouts  map[string]time.Duration `json:"timeouts" valid:"optional"`
+	}
+	obj := param{}
+	body := `{"dates": ["2020-01-02", "2020-01-03"], "stamps": [1588327200], "holidays": {"new_year": "2021-01-01"},
+		"intervals": ["2h", 1000000000], "timeouts": {"read": "5s"}}`
+	req := request("POST", "/", body, ContentTypeJson)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC), obj.Dates[1])
+	assert.True(t, time.Unix(1588327200, 0).Equal(*(*obj.Stamps)[0]))
+	assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), obj.Holidays["new_year"])
+	assert.Equal(t, []time.Duration{2 * time.Hour, time.Second}, obj.Intervals)
+	assert.Equal(t, 5*time.Second, obj.Timeouts["read"])
+
+	body = `{"dates": ["2020-01-02", "02/01/2020"], "holidays": {"a": "2021-01-01", "b": "x"}, "intervals": ["4h"],
+		"timeouts": {"read": "soon"}}`
+	req = request("POST", "/", body, ContentTypeJson)
+	err := Bind(req, &param{})
+	assert.Error(t, err)
+	assert.Equal(t, "dates: time expected in format `2006-01-02`; holidays.b: time expected in format `2006-01-02`; "+
+		"intervals: greater than 3h; timeouts.read: duration expected", err.Error())
+}
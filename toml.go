@@ -0,0 +1,127 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+// tomlDocument returns the toml value v, as decoded by the toml package, at
+// path. lines holds the line of the keys by path.
+func tomlDocument(v interface{}, path string, line int, lines map[string]int) *document {
+	if l, ok := lines[path]; ok {
+		line = l
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		doc := &document{line: line, fields: make(map[string]*document, len(v))}
+		for key, value := range v {
+			doc.fields[key] = tomlDocument(value, joinPath(path, key), line, lines)
+		}
+		return doc
+	case []interface{}:
+		doc := &document{line: line, items: make([]*document, 0, len(v))}
+		for i, item := range v {
+			doc.items = append(doc.items, tomlDocument(item, joinPath(path, strconv.Itoa(i)), line, lines))
+		}
+		return doc
+	case time.Time:
+		s := v.Format(time.RFC3339Nano)
+		return &document{line: line, scalar: &s}
+	default:
+		s := fmt.Sprint(v)
+		return &document{line: line, scalar: &s}
+	}
+}
+
+// tomlLines returns the line of every key of the toml body data by path, with
+// the index of the item for an array of tables, e.g. `items.1.sku`.
+func tomlLines(data []byte) map[string]int {
+	t := &tomlPositions{lines: make(map[string]int), arrays: make(map[string]int)}
+	t.p.Reset(data)
+	var table string
+	for t.p.NextExpression() {
+		e := t.p.Expression()
+		switch e.Kind {
+		case unstable.Table:
+			table = t.keyPath("", e.Key(), false)
+		case unstable.ArrayTable:
+			table = t.keyPath("", e.Key(), true)
+		case unstable.KeyValue:
+			t.keyValue(table, e)
+		}
+	}
+	return t.lines
+}
+
+type tomlPositions struct {
+	p     unstable.Parser
+	lines map[string]int
+	// arrays holds the index of the last item of every array of tables.
+	arrays map[string]int
+}
+
+// keyPath records the lines of the dotted key under base, and returns its
+// path. A key of an array of tables refers to its last item, or to a new one
+// for the header of an item.
+func (t *tomlPositions) keyPath(base string, key unstable.Iterator, array bool) string {
+	path := base
+	for key.Next() {
+		k := key.Node()
+		path = joinPath(path, string(k.Data))
+		if array && key.IsLast() {
+			i := 0
+			if last, ok := t.arrays[path]; ok {
+				i = last + 1
+			}
+			t.arrays[path] = i
+			t.mark(path, k.Raw)
+			path = joinPath(path, strconv.Itoa(i))
+		} else if i, ok := t.arrays[path]; ok {
+			path = joinPath(path, strconv.Itoa(i))
+		}
+		t.mark(path, k.Raw)
+	}
+	return path
+}
+
+// keyValue records the lines of the key value e under table, and of the keys
+// of its inline tables.
+func (t *tomlPositions) keyValue(table string, e *unstable.Node) {
+	path := t.keyPath(table, e.Key(), false)
+	t.value(path, e.Value())
+}
+
+func (t *tomlPositions) value(path string, v *unstable.Node) {
+	switch v.Kind {
+	case unstable.InlineTable:
+		children := v.Children()
+		for children.Next() {
+			if kv := children.Node(); kv.Kind == unstable.KeyValue {
+				t.keyValue(path, kv)
+			}
+		}
+	case unstable.Array:
+		children := v.Children()
+		for i := 0; children.Next(); {
+			item := children.Node()
+			if item.Kind == unstable.Comment {
+				continue
+			}
+			itemPath := joinPath(path, strconv.Itoa(i))
+			t.mark(itemPath, item.Raw)
+			t.value(itemPath, item)
+			i++
+		}
+	}
+}
+
+// mark records the line of raw for path, unless it is known already.
+func (t *tomlPositions) mark(path string, raw unstable.Range) {
+	if _, ok := t.lines[path]; ok || raw.Length == 0 {
+		return
+	}
+	t.lines[path] = t.p.Shape(raw).Start.Line
+}
@@ -0,0 +1,112 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// stringTransforms are the transforms of the `transform` tag without param.
+var stringTransforms = map[string]func(string) string{
+	"trim":  strings.TrimSpace,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	// collapse_spaces also trims, as strings.Fields drops leading and trailing space
+	"collapse_spaces": func(s string) string { return strings.Join(strings.Fields(s), " ") },
+	"unicode_nfc":     norm.NFC.String,
+	"strip_control": func(s string) string {
+		return strings.Map(func(c rune) rune {
+			if unicode.IsControl(c) && c != '\t' && c != '\n' && c != '\r' {
+				return -1
+			}
+			return c
+		}, s)
+	},
+}
+
+// parseTransforms parses the `transform` tag, a comma separated list of
+// transforms applied in order to a string before it is validated, e.g.
+// `transform:"trim,lower,truncate=32"`.
+func (r *rules) parseTransforms(tag reflect.StructTag, t, vt reflect.Type) {
+	param := tag.Get("transform")
+	if len(param) == 0 {
+		return
+	}
+	if vt.Kind() != reflect.String || isCoercible(vt) {
+		r.notApplicable("transform", param, t)
+		return
+	}
+
+	for _, name := range strings.Split(param, ",") {
+		if fn, ok := stringTransforms[name]; ok {
+			r.transforms = append(r.transforms, fn)
+			continue
+		}
+		if strings.HasPrefix(name, "truncate=") {
+			n, err := strconv.Atoi(name[len("truncate="):])
+			if err == nil && n >= 0 {
+				r.transforms = append(r.transforms, func(s string) string { return truncate(s, n) })
+				continue
+			}
+		}
+		r.fail(&FieldError{Tag: "transform", Param: param, Code: CodeInvalidTag,
+			Message: fmt.Sprintf(ERR_INVALID_TRANSFORM_TAG, name)})
+		r.transforms = nil
+		return
+	}
+}
+
+// transform applies the `transform` tag of r to s.
+func (r *rules) transform(s string) string {
+	for _, fn := range r.transforms {
+		s = fn(s)
+	}
+	return s
+}
+
+// transformStrings applies the `transform` tag of r to the strings held by
+// v, which is already set, such as a field decoded from json.
+func transformStrings(v reflect.Value, r *rules) {
+	if len(r.transforms) == 0 {
+		return
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(r.transform(v.String()))
+	case reflect.Ptr:
+		if !v.IsNil() {
+			transformStrings(v.Elem(), r)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			transformStrings(v.Index(i), r)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			e := reflect.New(v.Type().Elem()).Elem()
+			e.Set(v.MapIndex(key))
+			transformStrings(e, r)
+			v.SetMapIndex(key, e)
+		}
+	}
+}
+
+// truncate returns the first n runes of s.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	i := 0
+	for j := range s {
+		if i == n {
+			return s[:j]
+		}
+		i++
+	}
+	return s
+}
@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type transformParam struct {
+	Side    string            `form:"side" json:"side" valid:"required" transform:"trim,lower" values:"front|back"`
+	Code    *string           `form:"code" json:"code" valid:"optional" transform:"upper" regexp:"^[A-Z]{2}$"`
+	Title   string            `form:"title" json:"title" valid:"optional" transform:"strip_control,collapse_spaces,truncate=12"`
+	Name    string            `form:"name" json:"name" valid:"optional" transform:"unicode_nfc" len:"3"`
+	Tags    []string          `form:"tags" json:"tags" valid:"optional" transform:"trim" values:"go|json"`
+	Labels  map[string]string `form:"labels" json:"labels" valid:"optional" transform:"lower"`
+	Comment string            `form:"comment" json:"comment" valid:"optional" transform:"trim"`
+}
+
+func TestTransform(t *testing.T) {
+	body := url.Values{}
+	body.Add("side", "  Front ")
+	body.Add("code", "fr")
+	body.Add("title", "  Hello\x00,   \t world  of Go ")
+	body.Add("name", "Zoe\u0301")
+	body.Add("tags", " go")
+	body.Add("tags", "json ")
+	body.Add("labels[color]", "RED")
+
+	obj := transformParam{}
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "front", obj.Side)
+	assert.Equal(t, "FR", *obj.Code)
+	assert.Equal(t, "Hello, world", obj.Title)
+	assert.Equal(t, "Zo\u00e9", obj.Name)
+	assert.Equal(t, []string{"go", "json"}, obj.Tags)
+	assert.Equal(t, map[string]string{"color": "red"}, obj.Labels)
+
+	obj = transformParam{}
+	req = request("POST", "/", `{"side": " BACK", "code": "us", "name": "Zoé", "tags": ["go "],
+		"labels": {"size": "XL"}, "title": "a", "comment": "x"}`, ContentTypeJson)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "back", obj.Side)
+	assert.Equal(t, "US", *obj.Code)
+	assert.Equal(t, "Zo\u00e9", obj.Name)
+	assert.Equal(t, []string{"go"}, obj.Tags)
+	assert.Equal(t, map[string]string{"size": "xl"}, obj.Labels)
+}
+
+func TestTransformBeforeValidation(t *testing.T) {
+	body := url.Values{}
+	body.Add("side", " side ")
+	body.Add("comment", "   ")
+
+	obj := transformParam{}
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "side: side is not in [front back]; comment: blank string", err.Error())
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "Zoé", truncate("Zoé", 3))
+	assert.Equal(t, "Zo", truncate("Zoé", 2))
+	assert.Equal(t, "", truncate("Zoé", 0))
+}
+
+type malformedTransformParam struct {
+	Name string `form:"name" valid:"required" transform:"trim,title"`
+	Bio  string `form:"bio" valid:"required" transform:"truncate=ten"`
+	Age  int    `form:"age" valid:"required" transform:"trim"`
+}
+
+func TestTransformMalformedTags(t *testing.T) {
+	err := Compile(&malformedTransformParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "Name: invalid `transform` tag, unknown transform title; "+
+		"Bio: invalid `transform` tag, unknown transform truncate=ten; "+
+		"Age: invalid `transform` tag, not allowed on int", err.Error())
+}
@@ -6,6 +6,9 @@ import (
 	"mime/multipart"
 	"net/http"
 	"os"
+	"reflect"
+	"sort"
+	"strings"
 )
 
 func filterFlags(content string) string {
@@ -17,6 +20,185 @@ func filterFlags(content string) string {
 	return content
 }
 
+// tagName returns the name given to a field by the format tag, dropping
+// options such as `,omitempty`. The parent elements of an xml tag, as in
+// `address>city`, are returned as a dotted path.
+func tagName(tag reflect.StructTag, format string) string {
+	name := tag.Get(format)
+	if i := strings.Index(name, ","); i >= 0 {
+		name = name[:i]
+	}
+	if format == "xml" {
+		name = strings.Replace(name, ">", ".", -1)
+	}
+	return name
+}
+
+var typeOfBytes = reflect.TypeOf([]byte(nil))
+
+// isNestedStruct reports whether fields of type t are bound one by one as
+// a nested struct, rather than from a single param.
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != typeOfTime && !isCoercible(t)
+}
+
+// isStructMap reports whether t is a map of structs, or of pointers to
+// structs, keyed by strings, whose values are bound field by field.
+func isStructMap(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && isNestedStruct(t.Elem())
+}
+
+// elemType returns the type of the values held by t, looking through
+// pointers and slices which are not coercible themselves.
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice && !isCoercible(t) {
+		t = t.Elem()
+	}
+	return t
+}
+
+// indirectStruct returns the struct held by v, a struct or a pointer to one,
+// and false for a nil pointer.
+func indirectStruct(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+// joinPath returns the dotted path of the field name nested under path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// formPath is the position of a field in a form, both in dotted
+// (`address.city`) and in bracketed (`address[city]`) syntax.
+type formPath struct {
+	dotted    string
+	bracketed string
+}
+
+func (p formPath) child(name string) formPath {
+	if p.dotted == "" {
+		return formPath{dotted: name, bracketed: name}
+	}
+	return formPath{dotted: p.dotted + "." + name, bracketed: p.bracketed + "[" + name + "]"}
+}
+
+// keys returns the form keys a param at p may be sent as.
+func (p formPath) keys() []string {
+	if p.dotted == p.bracketed {
+		return []string{p.dotted, p.dotted + "[]"}
+	}
+	return []string{p.dotted, p.dotted + "[]", p.bracketed, p.bracketed + "[]"}
+}
+
+// present reports whether any param or file is nested under p.
+func (p formPath) present(formData map[string][]string, formFile map[string][]*multipart.FileHeader) bool {
+	for key := range formData {
+		if strings.HasPrefix(key, p.dotted+".") || strings.HasPrefix(key, p.bracketed+"[") {
+			return true
+		}
+	}
+	for key := range formFile {
+		if strings.HasPrefix(key, p.dotted+".") || strings.HasPrefix(key, p.bracketed+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// sent reports whether a param or file is sent at p, or nested under it.
+func (p formPath) sent(formData map[string][]string, formFile map[string][]*multipart.FileHeader) bool {
+	for _, key := range p.keys() {
+		if len(formData[key]) > 0 || len(formFile[key]) > 0 {
+			return true
+		}
+	}
+	return p.present(formData, formFile)
+}
+
+// entries returns the params nested one level under p, keyed by the part
+// after p, e.g. `color` for both `meta[color]` and `meta.color`.
+func (p formPath) entries(formData map[string][]string) map[string][]string {
+	entries := make(map[string][]string)
+	for key, vs := range formData {
+		var rest string
+		key = strings.TrimSuffix(key, "[]")
+		if strings.HasPrefix(key, p.bracketed+"[") && strings.HasSuffix(key, "]") {
+			rest = key[len(p.bracketed)+1 : len(key)-1]
+		} else if strings.HasPrefix(key, p.dotted+".") {
+			rest = key[len(p.dotted)+1:]
+		}
+		if rest == "" || strings.ContainsAny(rest, ".[]") || len(vs) == 0 {
+			continue
+		}
+		entries[rest] = append(entries[rest], vs...)
+	}
+	return entries
+}
+
+// sortedKeys returns the keys of m in increasing order.
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// tagKeys returns the keys of tag in the order they are written, following
+// the conventional format parsed by reflect.StructTag.Get.
+func tagKeys(tag reflect.StructTag) []string {
+	var keys []string
+	for tag != "" {
+		// Skip leading space
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// Scan to colon, then over the quoted string
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		name := string(tag[:i])
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		tag = tag[i+1:]
+		keys = append(keys, name)
+	}
+	return keys
+}
+
 // Check str is in values
 func isIn(str string, values []string) bool {
 	for _, value := range values {
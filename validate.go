@@ -1,139 +1,304 @@
 package validator
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
-	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 )
 
-// Validate check the value of the param by tag. If not valid then return error
-func validate(obj interface{}, format string) error {
+// Validatable is implemented by structs with cross-field rules, e.g. "end_date
+// must be after start_date". Validate is called once every field of the
+// struct, including nested ones, is valid.
+type Validatable interface {
+	Validate() error
+}
+
+// ContextValidatable is like Validatable, but is given the context of the request.
+type ContextValidatable interface {
+	Validate(ctx context.Context) error
+}
+
+var (
+	typeOfValidatable        = reflect.TypeOf((*Validatable)(nil)).Elem()
+	typeOfContextValidatable = reflect.TypeOf((*ContextValidatable)(nil)).Elem()
+)
+
+// validateHooks calls the Validate method of obj and of its nested structs,
+// innermost first. Errors and FieldError values returned are merged with the
+// path of the struct prepended, any other error is reported for the struct.
+func validateHooks(ctx context.Context, obj interface{}, format string) Errors {
+	var errs Errors
 	val := reflect.ValueOf(obj).Elem()
+	validateStructHooks(ctx, val, schemaOf(val.Type()), "", format, &errs)
+	return errs
+}
+
+func validateStructHooks(ctx context.Context, val reflect.Value, s *structSchema, path string, format string,
+	errs *Errors) {
+	for _, f := range s.fields {
+		field := val.Field(f.index)
+		if _, ok := f.name(format); !ok {
+			continue
+		}
+		switch {
+		case f.kind == fieldNested:
+			if field, ok := indirectStruct(field); ok {
+				validateStructHooks(ctx, field, schemaOf(field.Type()), f.path(path, format), format, errs)
+			}
+		case isNestedStruct(elemType(field.Type())):
+			// The structs of a slice field, at their index
+			for field.Kind() == reflect.Ptr && !field.IsNil() {
+				field = field.Elem()
+			}
+			if field.Kind() != reflect.Slice {
+				continue
+			}
+			for i := 0; i < field.Len(); i++ {
+				if elem, ok := indirectStruct(field.Index(i)); ok {
+					validateStructHooks(ctx, elem, schemaOf(elem.Type()),
+						joinPath(f.path(path, format), strconv.Itoa(i)), format, errs)
+				}
+			}
+		case isStructMap(field.Type()):
+			// The structs of a map field, at their key
+			keys := field.MapKeys()
+			sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+			for _, key := range keys {
+				// Map values are not addressable
+				elem := reflect.New(field.Type().Elem()).Elem()
+				elem.Set(field.MapIndex(key))
+				if elem, ok := indirectStruct(elem); ok {
+					validateStructHooks(ctx, elem, schemaOf(elem.Type()),
+						joinPath(f.path(path, format), key.String()), format, errs)
+				}
+			}
+		}
+	}
+	if !s.hook {
+		return
+	}
+
+	var err error
+	switch v := val.Addr().Interface().(type) {
+	case ContextValidatable:
+		err = v.Validate(ctx)
+	case Validatable:
+		err = v.Validate()
+	}
+	if err == nil {
+		return
+	}
+
+	var fieldErrs Errors
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErrs) {
+		for _, e := range fieldErrs {
+			e := *e
+			errs.add(joinPath(path, e.Field), &e)
+		}
+	} else if errors.As(err, &fieldErr) {
+		e := *fieldErr
+		errs.add(joinPath(path, e.Field), &e)
+	} else {
+		errs.add(path, &FieldError{Code: CodeInvalidStruct, Message: err.Error()})
+	}
+}
 
-	for i := 0; i < val.NumField(); i++ {
-		tag := val.Type().Field(i).Tag
-		name := tag.Get(format)
-		field := val.Field(i)
+// validateRules runs the validations registered by RegisterValidation for the
+// tags of field, in the order of the tags. Absent fields are skipped.
+func validateRules(field reflect.Value, f *fieldSchema, parent reflect.Value, path string) *FieldError {
+	if (field.Kind() == reflect.Ptr || field.Kind() == reflect.Map) && field.IsNil() {
+		return nil
+	}
+	for _, key := range f.tagKeys {
+		fn := validation(key)
+		if fn == nil {
+			continue
+		}
+		param := f.tag.Get(key)
+		err := fn(FieldContext{Field: path, Param: param, Value: field, Tag: f.tag, Parent: parent})
+		if err == nil {
+			continue
+		}
 
-		if err := validateField(field, tag); err != nil {
-			return fmt.Errorf("%s: %s", name, err.Error())
+		var fieldErr *FieldError
+		var code ErrorCode
+		if errors.As(err, &fieldErr) {
+			e := *fieldErr
+			if e.Tag == "" {
+				e.Tag, e.Param = key, param
+			}
+			return &e
+		} else if errors.As(err, &code) {
+			return &FieldError{Tag: key, Param: param, Value: field.Interface(), Code: code, Message: code.Error()}
 		}
+		return &FieldError{Tag: key, Param: param, Value: field.Interface(), Code: ErrorCode(key),
+			Message: err.Error()}
 	}
 	return nil
 }
 
-// TODO: more check
-func validateField(v reflect.Value, tag reflect.StructTag) (err error) {
+// validateMap checks the map v against the `keys`, `min_items` and `max_items`
+// tags, then validates every value by the other tags or the `dive` tag.
+func validateMap(v reflect.Value, r *rules, path string, errs *Errors) {
+	if err := r.tagError(); err != nil {
+		errs.add(path, err)
+		return
+	}
+	if err := validateItems(v, r); err != nil {
+		errs.add(path, err)
+		return
+	}
+
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	for _, key := range keys {
+		name := fmt.Sprint(key.Interface())
+		if r.keys != nil && !isIn(name, r.keys) {
+			errs.add(joinPath(path, name), &FieldError{Tag: "keys", Param: strings.Join(r.keys, "|"), Value: name,
+				Code: CodeInvalidMapKey, Message: fmt.Sprintf(ERR_INVALID_MAP_KEY, name, r.keys)})
+			continue
+		}
+		if err := validateField(v.MapIndex(key), r.elemRules()); err != nil {
+			errs.add(joinPath(path, name), err)
+		}
+	}
+}
+
+// validateField checks v by the built-in tags, see validateRules for the
+// registered ones.
+func validateField(v reflect.Value, r *rules) *FieldError {
+	if err := r.tagError(); err != nil {
+		return err
+	}
+
+	switch v.Type() {
+	case typeOfTime:
+		return validateTime(v.Interface().(time.Time), r)
+	case typeOfDuration:
+		return validateDuration(time.Duration(v.Int()), r)
+	}
+
 	switch v.Kind() {
+	case reflect.Ptr:
+		// Absent param
+		if v.IsNil() {
+			return nil
+		}
+		return validateField(v.Elem(), r)
 	case reflect.String:
 		if !utf8.Valid([]byte(v.String())) {
-			return fmt.Errorf(ERR_INVALID_UTF8_STRING)
+			return &FieldError{Value: v.String(), Code: CodeInvalidUTF8String, Message: ERR_INVALID_UTF8_STRING}
+		}
+		if len(v.String()) == 0 && r.allowEmpty {
+			return nil
 		}
 		if len(v.String()) == 0 {
-			return fmt.Errorf(ERR_BLANK_STRING)
+			return &FieldError{Value: v.String(), Code: CodeBlankString, Message: ERR_BLANK_STRING}
 		}
-		if len(tag.Get("values")) != 0 {
-			values := strings.Split(tag.Get("values"), "|")
-			if !isIn(v.String(), values) {
-				return fmt.Errorf(ERR_INVALID_ENUMERATION, v.String(), values)
-			}
+		if r.trimBlank && strings.TrimSpace(v.String()) == "" {
+			return &FieldError{Tag: "nonblank", Param: "trim", Value: v.String(), Code: CodeBlankString,
+				Message: ERR_BLANK_STRING}
 		}
-		if len(tag.Get("regexp")) != 0 {
-			re := regexp.MustCompile(tag.Get("regexp"))
-			if !re.MatchString(v.String()) {
-				return fmt.Errorf(ERR_WRONG_FORMAT, re)
-			}
+		if err := validateLength(v.String(), r); err != nil {
+			return err
+		}
+		if err := validateValues(v, r); err != nil {
+			return err
+		}
+		if r.regexp != nil && !r.regexp.MatchString(v.String()) {
+			return &FieldError{Tag: "regexp", Param: r.regexp.String(), Value: v.String(),
+				Code: CodeWrongFormat, Message: fmt.Sprintf(ERR_WRONG_FORMAT, r.regexp)}
+		}
+		if err := validateFormat(v.String(), r); err != nil {
+			return err
 		}
 	case reflect.Slice:
 		// []byte
-		var typeOfBytes = reflect.TypeOf([]byte(nil))
-		if tag.Get("type") == "file" && v.Type() == typeOfBytes {
-			if len(tag.Get("max_size")) != 0 {
-				max_size, err := strconv.ParseInt(tag.Get("max_size"), 10, 64)
-				if err != nil {
-					return fmt.Errorf(ERR_INVALID_MAX_SIZE_TAG)
-				}
-				if len(v.Bytes()) > int(max_size) {
-					return fmt.Errorf(ERR_PARAM_FILE_TOO_LARGE, max_size)
-				}
+		if r.maxSize != nil && v.Type() == typeOfBytes {
+			if int64(len(v.Bytes())) > r.maxSize.i {
+				return &FieldError{Tag: "max_size", Param: r.maxSize.param, Value: len(v.Bytes()),
+					Code: CodeFileTooLarge, Message: fmt.Sprintf(ERR_PARAM_FILE_TOO_LARGE, r.maxSize.i)}
 			}
 		}
-		// Other
+		// Custom type such as net.IP, it is not a list of params
+		if isCoercible(v.Type()) {
+			return validateValues(v, r)
+		}
+		// List of params
+		if err := validateItems(v, r); err != nil {
+			return err
+		}
 		for i := 0; i < v.Len(); i++ {
-			err = validateField(v.Index(i), tag)
-			if err != nil {
+			if err := validateField(v.Index(i), r.elemRules()); err != nil {
 				return err
 			}
 		}
-	case reflect.Int:
-		if len(tag.Get("max")) != 0 {
-			max, err := strconv.ParseInt(tag.Get("max"), 10, 64)
-			if err != nil {
-				return fmt.Errorf(ERR_INVALID_MAX_TAG)
-			}
-			if v.Int() > max {
-				return fmt.Errorf(ERR_GREATER_THAN_MAX, tag.Get("max"))
-			}
+	case reflect.Bool, reflect.Struct:
+		// Struct of a custom type
+		return validateValues(v, r)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if err := validateValues(v, r); err != nil {
+			return err
 		}
-		if len(tag.Get("min")) != 0 {
-			min, err := strconv.ParseInt(tag.Get("min"), 10, 64)
-			if err != nil {
-				return fmt.Errorf(ERR_INVALID_MIN_TAG)
-			}
-			if v.Int() < min {
-				return fmt.Errorf(ERR_SMALLER_THAN_MIN, tag.Get("min"))
-			}
+		if r.max != nil && v.Int() > r.max.i {
+			return greaterThanMax(v, r)
 		}
-		if len(tag.Get("range")) != 0 {
-			r := strings.Split(tag.Get("range"), "|")
-			min, err := strconv.ParseInt(r[0], 10, 64)
-			if err != nil {
-				return fmt.Errorf(ERR_INVALID_RANGE_TAG)
-			}
-			max, err := strconv.ParseInt(r[1], 10, 64)
-			if err != nil {
-				return fmt.Errorf(ERR_INVALID_RANGE_TAG)
-			}
-			if v.Int() < min || v.Int() > max {
-				return fmt.Errorf(ERR_NOT_IN_RANGE, r[0], r[1])
-			}
+		if r.min != nil && v.Int() < r.min.i {
+			return smallerThanMin(v, r)
+		}
+		if r.rng[0] != nil && r.rng[1] != nil && (v.Int() < r.rng[0].i || v.Int() > r.rng[1].i) {
+			return notInRange(v, r)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if err := validateValues(v, r); err != nil {
+			return err
+		}
+		if r.max != nil && v.Uint() > r.max.u {
+			return greaterThanMax(v, r)
+		}
+		if r.min != nil && v.Uint() < r.min.u {
+			return smallerThanMin(v, r)
+		}
+		if r.rng[0] != nil && r.rng[1] != nil && (v.Uint() < r.rng[0].u || v.Uint() > r.rng[1].u) {
+			return notInRange(v, r)
 		}
 	case reflect.Float32, reflect.Float64:
-		if len(tag.Get("max")) != 0 {
-			max, err := strconv.ParseFloat(tag.Get("max"), 64)
-			if err != nil {
-				return fmt.Errorf(ERR_INVALID_MAX_TAG)
-			}
-			if v.Float() > max {
-				return fmt.Errorf(ERR_GREATER_THAN_MAX, tag.Get("max"))
-			}
+		if err := validateValues(v, r); err != nil {
+			return err
 		}
-		if len(tag.Get("min")) != 0 {
-			min, err := strconv.ParseFloat(tag.Get("min"), 64)
-			if err != nil {
-				return fmt.Errorf(ERR_INVALID_MIN_TAG)
-			}
-			if v.Float() < min {
-				return fmt.Errorf(ERR_SMALLER_THAN_MIN, tag.Get("min"))
-			}
+		if r.max != nil && v.Float() > r.max.f {
+			return greaterThanMax(v, r)
 		}
-		if len(tag.Get("range")) != 0 {
-			r := strings.Split(tag.Get("range"), "|")
-			min, err := strconv.ParseFloat(r[0], 64)
-			if err != nil {
-				return fmt.Errorf(ERR_INVALID_RANGE_TAG)
-			}
-			max, err := strconv.ParseFloat(r[1], 64)
-			if err != nil {
-				return fmt.Errorf(ERR_INVALID_RANGE_TAG)
-			}
-			if v.Float() < min || v.Float() > max {
-				return fmt.Errorf(ERR_NOT_IN_RANGE, r[0], r[1])
-			}
+		if r.min != nil && v.Float() < r.min.f {
+			return smallerThanMin(v, r)
+		}
+		if r.rng[0] != nil && r.rng[1] != nil && (v.Float() < r.rng[0].f || v.Float() > r.rng[1].f) {
+			return notInRange(v, r)
 		}
 	}
 	return nil
 }
+
+func greaterThanMax(v reflect.Value, r *rules) *FieldError {
+	return &FieldError{Tag: "max", Param: r.max.param, Value: v.Interface(), Code: CodeGreaterThanMax,
+		Message: fmt.Sprintf(ERR_GREATER_THAN_MAX, r.max.param)}
+}
+
+func smallerThanMin(v reflect.Value, r *rules) *FieldError {
+	return &FieldError{Tag: "min", Param: r.min.param, Value: v.Interface(), Code: CodeSmallerThanMin,
+		Message: fmt.Sprintf(ERR_SMALLER_THAN_MIN, r.min.param)}
+}
+
+func notInRange(v reflect.Value, r *rules) *FieldError {
+	return &FieldError{Tag: "range", Param: r.rng[0].param + "|" + r.rng[1].param, Value: v.Interface(),
+		Code: CodeNotInRange, Message: fmt.Sprintf(ERR_NOT_IN_RANGE, r.rng[0].param, r.rng[1].param)}
+}
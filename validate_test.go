@@ -3,6 +3,7 @@ package validator
 import (
 	//"fmt"
 	"encoding/base64"
+	"errors"
 	"net/url"
 	"testing"
 
@@ -153,6 +154,15 @@ func TestBase64(t *testing.T) {
 	err := Bind(req, &obj)
 	assert.NoError(t, err)
 	assert.Equal(t, []byte("hello"), obj.Label)
+
+	// A file sent instead of the string
+	req = requestMultipartForm("/", nil, map[string]string{"label": "testdata/broken.jpg"})
+	assert.NotPanics(t, func() {
+		err = Bind(req, &base64Param{})
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "label: invalid base64 string", err.Error())
+	assert.True(t, errors.Is(err, CodeInvalidBase64))
 }
 
 func TestMinIntTag(t *testing.T) {
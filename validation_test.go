@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	RegisterValidation("prefix", func(fc FieldContext) error {
+		if !strings.HasPrefix(fc.Value.String(), fc.Param) {
+			return fmt.Errorf("should start with %s", fc.Param)
+		}
+		return nil
+	})
+	RegisterValidation("tenant", func(fc FieldContext) error {
+		tenant := fc.Parent.FieldByName("Tenant").String()
+		if !strings.HasPrefix(fc.Value.String(), tenant+"-") {
+			return &FieldError{Code: "wrong_tenant", Message: "not owned by " + tenant}
+		}
+		return nil
+	})
+	RegisterValidation("even", func(fc FieldContext) error {
+		if fc.Value.Int()%2 != 0 {
+			return ErrorCode("odd")
+		}
+		return nil
+	})
+	RegisterValidation("not_city", func(fc FieldContext) error {
+		if reflect.Indirect(fc.Value).FieldByName("City").String() == fc.Param {
+			return fmt.Errorf("should not be in %s", fc.Param)
+		}
+		return nil
+	})
+}
+
+type customRuleParam struct {
+	Tenant string  `form:"tenant" json:"tenant" valid:"required"`
+	Sku    string  `form:"sku" json:"sku" valid:"required" values:"SKU-1|SKU-2|XXX-1" prefix:"SKU-"`
+	Order  string  `form:"order" json:"order" valid:"required" tenant:""`
+	Count  int     `form:"count" json:"count" valid:"required" max:"10" even:""`
+	Extra  *string `form:"extra" json:"extra" valid:"optional" prefix:"x"`
+}
+
+func TestRegisterValidation(t *testing.T) {
+	obj := customRuleParam{}
+
+	body := url.Values{}
+	body.Add("tenant", "acme")
+	body.Add("sku", "SKU-1")
+	body.Add("order", "acme-42")
+	body.Add("count", "4")
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.NoError(t, err)
+
+	body = url.Values{}
+	body.Add("tenant", "acme")
+	body.Add("sku", "XXX-1")
+	body.Add("order", "other-42")
+	body.Add("count", "3")
+	body.Add("extra", "y")
+	req = request("POST", "/", body.Encode(), ContentTypeForm)
+	err = Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "sku: should start with SKU-; order: not owned by acme; count: odd; extra: should start with x",
+		err.Error())
+
+	errs := err.(Errors)
+	assert.Equal(t, &FieldError{Field: "sku", Tag: "prefix", Param: "SKU-", Value: "XXX-1", Code: "prefix",
+		Message: "should start with SKU-"}, errs[0])
+	assert.Equal(t, ErrorCode("wrong_tenant"), errs[1].Code)
+	assert.Equal(t, "tenant", errs[1].Tag)
+	assert.True(t, errors.Is(err, ErrorCode("odd")))
+}
+
+func TestRegisterValidationJson(t *testing.T) {
+	obj := customRuleParam{}
+
+	body := `{"tenant": "acme", "sku": "SKU-3", "order": "other-1", "count": 12}`
+	req := request("POST", "/", body, ContentTypeJson)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "sku: SKU-3 is not in [SKU-1 SKU-2 XXX-1]; order: not owned by acme; count: greater than 10",
+		err.Error())
+}
+
+type shippingParam struct {
+	To   addressParam  `form:"to" json:"to" yaml:"to" valid:"required" not_city:"Paris"`
+	From *addressParam `form:"from" json:"from" yaml:"from" valid:"optional" not_city:"Paris"`
+}
+
+func TestRegisterValidationNested(t *testing.T) {
+	req := request("GET", "/?to.city=Paris&to.zip=75001&from.city=Paris&from.zip=75002", "", "")
+	err := Bind(req, &shippingParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "to: should not be in Paris; from: should not be in Paris", err.Error())
+
+	body := `{"to": {"city": "Paris", "zip": 75001}, "from": {"city": "Lyon", "zip": 69001}}`
+	req = request("POST", "/", body, ContentTypeJson)
+	err = Bind(req, &shippingParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "to: should not be in Paris", err.Error())
+
+	body = "to: {city: Lyon, zip: 69001}\nfrom: {city: Paris, zip: 75002}\n"
+	req = request("POST", "/", body, ContentTypeYaml)
+	err = Bind(req, &shippingParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "from: should not be in Paris (line 2)", err.Error())
+
+	// Rules are not run on a struct whose fields are invalid
+	req = request("POST", "/", `{"to": {"city": "Paris"}}`, ContentTypeJson)
+	err = Bind(req, &shippingParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "to.zip: not found", err.Error())
+}
@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// valueSet is a parsed `values` or `not_values` tag.
+type valueSet struct {
+	param  string
+	values []reflect.Value
+}
+
+// parseValueSet parses the tag name, a list of values separated by `|`, as
+// values of type t, so that they are compared by value rather than by text.
+func (r *rules) parseValueSet(tag reflect.StructTag, name string, t, vt reflect.Type) *valueSet {
+	param := tag.Get(name)
+	if len(param) == 0 {
+		return nil
+	}
+	switch vt.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+	default:
+		if !isCoercible(vt) || vt == typeOfTime {
+			r.notApplicable(name, param, t)
+			return nil
+		}
+	}
+
+	set := &valueSet{param: param}
+	// Values are coerced as params are, but not transformed
+	coercion := &rules{timeFormat: r.timeFormat}
+	for _, s := range strings.Split(param, "|") {
+		v := reflect.New(vt).Elem()
+		if err := setValue(v, s, coercion); err != nil {
+			r.fail(&FieldError{Tag: name, Param: param, Code: CodeInvalidTag,
+				Message: fmt.Sprintf(ERR_INVALID_VALUES_TAG, name, invalidParam(v, s, coercion).Message)})
+			return nil
+		}
+		set.values = append(set.values, v)
+	}
+	return set
+}
+
+// contains reports whether v equals one of the values of set.
+func (set *valueSet) contains(v reflect.Value) bool {
+	for _, value := range set.values {
+		if equalValues(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues reports whether a and b, of the same kind, are equal. Numbers
+// are compared by value.
+func equalValues(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.String:
+		return a.String() == b.String()
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// validateValues checks v against the `values` and `not_values` tags.
+func validateValues(v reflect.Value, r *rules) *FieldError {
+	if r.values != nil && !r.values.contains(v) {
+		return &FieldError{Tag: "values", Param: r.values.param, Value: v.Interface(), Code: CodeInvalidEnumeration,
+			Message: fmt.Sprintf(ERR_INVALID_ENUMERATION, fmt.Sprint(v.Interface()), strings.Split(r.values.param, "|"))}
+	}
+	if r.notValues != nil && r.notValues.contains(v) {
+		return &FieldError{Tag: "not_values", Param: r.notValues.param, Value: v.Interface(), Code: CodeExcludedValue,
+			Message: fmt.Sprintf(ERR_EXCLUDED_VALUE, fmt.Sprint(v.Interface()))}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package validator
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type status int
+
+type valuesParam struct {
+	Page    int       `form:"page" json:"page" valid:"optional" values:"1|2|3" not_values:"2"`
+	Ratio   float32   `form:"ratio" json:"ratio" valid:"optional" values:"0.1|0.5|1"`
+	Size    uint8     `form:"size" json:"size" valid:"optional" values:"08|16"`
+	Active  bool      `form:"active" json:"active" valid:"optional" values:"true"`
+	Status  status    `form:"status" json:"status" valid:"optional" values:"0|1|2"`
+	Color   color     `form:"color" json:"color" valid:"optional" values:"red"`
+	Price   cents     `form:"price" json:"price" valid:"optional" not_values:"0.00"`
+	Tags    []int     `form:"tags" json:"tags" valid:"optional" dive:"not_values=13|666"`
+	Country string    `form:"country" json:"country" valid:"optional" not_values:"XX|ZZ"`
+	Codes   *[]string `form:"codes" json:"codes" valid:"optional" values:"a|b"`
+}
+
+func TestValues(t *testing.T) {
+	body := url.Values{}
+	body.Add("page", "3")
+	body.Add("ratio", "0.1")
+	body.Add("size", "8")
+	body.Add("active", "1")
+	body.Add("status", "2")
+	body.Add("color", "red")
+	body.Add("price", "1.50")
+	body.Add("tags", "1")
+	body.Add("tags", "42")
+	body.Add("country", "FR")
+	body.Add("codes", "a")
+
+	obj := valuesParam{}
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, float32(0.1), obj.Ratio)
+
+	obj = valuesParam{}
+	req = request("POST", "/", `{"page": 1, "ratio": 1.0, "size": 16, "active": true, "status": 0,
+		"color": "red", "price": "2.00", "tags": [7], "country": "FR"}`, ContentTypeJson)
+	assert.NoError(t, Bind(req, &obj))
+}
+
+func TestValuesFailed(t *testing.T) {
+	body := url.Values{}
+	body.Add("page", "2")
+	body.Add("ratio", "0.2")
+	body.Add("size", "9")
+	body.Add("active", "false")
+	body.Add("status", "3")
+	body.Add("color", "green")
+	body.Add("price", "0")
+	body.Add("tags", "1")
+	body.Add("tags", "13")
+	body.Add("country", "XX")
+	body.Add("codes", "c")
+
+	obj := valuesParam{}
+	req := request("POST", "/", body.Encode(), ContentTypeForm)
+	err := Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "page: 2 is not allowed; ratio: 0.2 is not in [0.1 0.5 1]; size: 9 is not in [08 16]; "+
+		"active: false is not in [true]; status: 3 is not in [0 1 2]; color: 2 is not in [red]; "+
+		"price: {0} is not allowed; tags: 13 is not allowed; country: XX is not allowed; "+
+		"codes: c is not in [a b]", err.Error())
+	assert.True(t, errors.Is(err, CodeInvalidEnumeration))
+	assert.True(t, errors.Is(err, CodeExcludedValue))
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "not_values", fieldErr.Tag)
+	assert.Equal(t, "2", fieldErr.Param)
+	assert.Equal(t, 2, fieldErr.Value)
+}
+
+func TestValuesIP(t *testing.T) {
+	type param struct {
+		IP      net.IP `form:"ip" json:"ip" valid:"required" values:"10.0.0.1|::1"`
+		Gateway net.IP `form:"gateway" json:"gateway" valid:"optional" not_values:"0.0.0.0"`
+	}
+	req := request("GET", "/?ip=10.0.0.1&gateway=10.0.0.254", "", "")
+	assert.NoError(t, Bind(req, &param{}))
+
+	req = request("GET", "/?ip=10.0.0.2&gateway=0.0.0.0", "", "")
+	err := Bind(req, &param{})
+	assert.Error(t, err)
+	assert.Equal(t, "ip: 10.0.0.2 is not in [10.0.0.1 ::1]; gateway: 0.0.0.0 is not allowed", err.Error())
+
+	req = request("POST", "/", `{"ip": "::1"}`, ContentTypeJson)
+	assert.NoError(t, Bind(req, &param{}))
+}
+
+type malformedValuesParam struct {
+	Page   int     `form:"page" valid:"required" values:"1|two"`
+	Ratio  float64 `form:"ratio" valid:"required" not_values:"x"`
+	Active bool    `form:"active" valid:"required" values:"yes"`
+	Tags   []int   `form:"tags" valid:"required" dive:"values=1|2" values:"1"`
+}
+
+func TestValuesMalformedTags(t *testing.T) {
+	err := Compile(&malformedValuesParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "Page: invalid `values` tag, int expected; "+
+		"Ratio: invalid `not_values` tag, float64 expected; "+
+		"Active: invalid `values` tag, bool expected; "+
+		"Tags: invalid `values` tag, not allowed on []int", err.Error())
+}
@@ -0,0 +1,300 @@
+package validator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// xmlNode is an element of an xml body, decoded as is before it is bound to
+// a struct field by field.
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Text    string     `xml:",chardata"`
+	Nodes   []xmlNode  `xml:",any"`
+}
+
+var typeOfXmlName = reflect.TypeOf(xml.Name{})
+
+// xmlChardata is the key of the character data of an element in its params.
+const xmlChardata = ",chardata"
+
+// params returns the texts of the child elements of n by name, the values of
+// its attributes by `@name`, and its character data, if not blank, by
+// xmlChardata. Repeated elements give several texts, as repeated form keys do.
+func (n *xmlNode) params() map[string][]string {
+	params := make(map[string][]string, len(n.Attrs)+len(n.Nodes)+1)
+	for _, attr := range n.Attrs {
+		params["@"+attr.Name.Local] = append(params["@"+attr.Name.Local], attr.Value)
+	}
+	for _, child := range n.Nodes {
+		params[child.XMLName.Local] = append(params[child.XMLName.Local], child.Text)
+	}
+	if strings.TrimSpace(n.Text) != "" {
+		params[xmlChardata] = []string{n.Text}
+	}
+	return params
+}
+
+// child returns the first child element of n called name.
+func (n *xmlNode) child(name string) (*xmlNode, bool) {
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Local == name {
+			return &n.Nodes[i], true
+		}
+	}
+	return nil, false
+}
+
+// descendant returns the first element of n at the path of names, such as
+// `address>city` in an `xml:"address>city"` tag, or an empty element.
+func (n *xmlNode) descendant(names []string) *xmlNode {
+	for _, name := range names {
+		child, ok := n.child(name)
+		if !ok {
+			return &xmlNode{}
+		}
+		n = child
+	}
+	return n
+}
+
+// decodeXml binds the xml element node to the struct val field by field, with
+// the semantics of decodeJson: child elements, or attributes with the `,attr`
+// option, are coerced as form params are, so that an absent one is reported
+// for a required field and set to the `default` of an optional one. Nested
+// elements are bound to nested structs. Invalid fields are reported in errs,
+// while a malformed body is returned as error.
+func decodeXml(node *xmlNode, val reflect.Value, path string, errs *Errors) error {
+	s := schemaOf(val.Type())
+	params := node.params()
+	present := make(map[*fieldSchema]bool, len(s.fields))
+	for _, f := range s.fields {
+		field := val.Field(f.index)
+		sf := val.Type().Field(f.index)
+		name, ok := f.name("xml")
+		if !ok {
+			continue
+		}
+		if sf.Type == typeOfXmlName {
+			if name != "" && node.XMLName.Local != "" && name != node.XMLName.Local {
+				return fmt.Errorf("expected element <%s> but have <%s>", name, node.XMLName.Local)
+			}
+			field.Set(reflect.ValueOf(node.XMLName))
+			continue
+		}
+		// Fields of an embedded struct are promoted
+		if f.embedded && name == "" {
+			if field.Kind() == reflect.Ptr {
+				if field.IsNil() {
+					field.Set(reflect.New(field.Type().Elem()))
+				}
+				field = field.Elem()
+			}
+			if err := decodeXml(node, field, path, errs); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key, fieldPath := xmlKey(sf, path)
+		if key == "" {
+			continue
+		}
+		parent, parentParams := node, params
+		if i := strings.LastIndex(key, ">"); i >= 0 {
+			parent = node.descendant(strings.Split(key[:i], ">"))
+			key, parentParams = key[i+1:], parent.params()
+		}
+		child, hasChild := parent.child(key)
+		n := len(*errs)
+		switch {
+		case f.kind == fieldNested:
+			present[f] = hasChild
+			if !hasChild {
+				if err := decodeAbsentXml(field, f.rules, fieldPath, errs); err != nil {
+					return err
+				}
+				continue
+			}
+			elem := field
+			if elem.Kind() == reflect.Ptr {
+				if elem.IsNil() {
+					elem.Set(reflect.New(elem.Type().Elem()))
+				}
+				elem = elem.Elem()
+			}
+			if err := decodeXml(child, elem, fieldPath, errs); err != nil {
+				return err
+			}
+			if len(*errs) > n {
+				continue
+			}
+		case isStructMap(sf.Type):
+			present[f] = hasChild
+			if !hasChild {
+				if f.rules.valid == "required" {
+					errs.add(fieldPath, &FieldError{Tag: "valid", Param: "required", Code: CodeParamNotFound,
+						Message: ERR_PARAM_NOT_FOUND})
+				}
+				continue
+			}
+			if err := decodeXmlEntries(child, field, fieldPath, errs); err != nil {
+				return err
+			}
+			if len(*errs) == n {
+				validateMap(field, f.rules, fieldPath, errs)
+			}
+			if len(*errs) > n {
+				continue
+			}
+		case f.kind == fieldMap:
+			present[f] = hasChild
+			entries := make(map[string][]string)
+			if hasChild {
+				for k, vs := range child.params() {
+					if k != xmlChardata && !strings.HasPrefix(k, "@") {
+						entries[joinPath(fieldPath, k)] = vs
+					}
+				}
+			}
+			coerceMap(field, formPath{dotted: fieldPath, bracketed: fieldPath}, f.rules, entries, errs)
+			if len(*errs) > n {
+				continue
+			}
+		case isNestedStruct(elemType(sf.Type)):
+			// Such as a slice of structs
+			present[f] = hasChild
+			if !hasChild {
+				if err := coerceField(field, nil, f.rules, nil, nil); err != nil {
+					if err.Code != codeOptionalParamNotFound {
+						errs.add(fieldPath, err)
+					}
+					continue
+				}
+			} else if err := decodeXmlElements(parent.Nodes, key, field, fieldPath, errs); err != nil {
+				return err
+			}
+			if err := validateField(field, f.rules); err != nil {
+				errs.add(fieldPath, err)
+				continue
+			}
+		default:
+			present[f] = len(parentParams[key]) > 0
+			err := coerceField(field, []string{key}, f.rules, parentParams, nil)
+			if err == nil {
+				err = validateField(field, f.rules)
+			}
+			if err != nil {
+				if err.Code != codeOptionalParamNotFound {
+					errs.add(fieldPath, err)
+				}
+				continue
+			}
+		}
+		if err := validateRules(field, f, val, fieldPath); err != nil {
+			errs.add(fieldPath, err)
+		}
+	}
+
+	sent := func(f *fieldSchema) bool { return present[f] }
+	validateConditions(val, s, path, "xml", sent, errs)
+	validateComparisons(val, s, path, "xml", sent, errs)
+	return nil
+}
+
+// xmlKey returns the key of the field sf in the params of its element, and
+// its path. The key is "" for the fields which are not bound, such as
+// `,innerxml` ones.
+func xmlKey(sf reflect.StructField, path string) (key, fieldPath string) {
+	tag := sf.Tag.Get("xml")
+	name, opts := tag, ""
+	if i := strings.Index(tag, ","); i >= 0 {
+		name, opts = tag[:i], tag[i+1:]
+	}
+	// Namespaces are not matched
+	if i := strings.LastIndex(name, " "); i >= 0 {
+		name = name[i+1:]
+	}
+	if name == "" {
+		name = sf.Name
+	}
+	switch opts {
+	case "", "omitempty":
+		// Parent elements are kept in the key, as in `address>city`
+		return name, joinPath(path, strings.Replace(name, ">", ".", -1))
+	case "attr", "attr,omitempty":
+		return "@" + name, joinPath(path, name)
+	case "chardata":
+		return xmlChardata, path
+	}
+	return "", ""
+}
+
+// decodeAbsentXml handles a struct or pointer-to-struct field whose element
+// is absent, as decodeAbsentJson does.
+func decodeAbsentXml(val reflect.Value, r *rules, path string, errs *Errors) error {
+	switch r.valid {
+	case "required":
+		errs.add(path, &FieldError{Tag: "valid", Param: "required", Code: CodeParamNotFound,
+			Message: ERR_PARAM_NOT_FOUND})
+		return nil
+	case "optional":
+		return nil
+	default:
+		// Absent pointers are left nil
+		if r.condition != nil || val.Kind() == reflect.Ptr {
+			return nil
+		}
+	}
+	return decodeXml(&xmlNode{}, val, path, errs)
+}
+
+// decodeXmlEntries binds the child elements of node to val, a map of structs,
+// keyed by their names, element by element with decodeXml, so that each
+// struct is validated at its key, e.g. `lines.a.sku`.
+func decodeXmlEntries(node *xmlNode, val reflect.Value, path string, errs *Errors) error {
+	m := reflect.MakeMapWithSize(val.Type(), len(node.Nodes))
+	for i := range node.Nodes {
+		key := node.Nodes[i].XMLName.Local
+		elem := reflect.New(val.Type().Elem()).Elem()
+		field := elem
+		if field.Kind() == reflect.Ptr {
+			field.Set(reflect.New(field.Type().Elem()))
+			field = field.Elem()
+		}
+		if err := decodeXml(&node.Nodes[i], field, joinPath(path, key), errs); err != nil {
+			return err
+		}
+		m.SetMapIndex(reflect.ValueOf(key).Convert(val.Type().Key()), elem)
+	}
+	val.Set(m)
+	return nil
+}
+
+// decodeXmlElements binds the elements of nodes called name to val, a slice
+// of structs, element by element with decodeXml, so that each struct is
+// validated at its index, e.g. `item.0.qty`.
+func decodeXmlElements(nodes []xmlNode, name string, val reflect.Value, path string, errs *Errors) error {
+	slice := reflect.MakeSlice(val.Type(), 0, len(nodes))
+	for i := range nodes {
+		if nodes[i].XMLName.Local != name {
+			continue
+		}
+		elem := reflect.New(val.Type().Elem()).Elem()
+		field := elem
+		if field.Kind() == reflect.Ptr {
+			field.Set(reflect.New(field.Type().Elem()))
+			field = field.Elem()
+		}
+		if err := decodeXml(&nodes[i], field, joinPath(path, strconv.Itoa(slice.Len())), errs); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	val.Set(slice)
+	return nil
+}
@@ -0,0 +1,147 @@
+package validator
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type xmlItem struct {
+	Sku string `xml:"sku,attr"`
+	Qty int    `xml:"qty"`
+}
+
+type xmlAddress struct {
+	City string `xml:"city" valid:"required"`
+	Zip  string `xml:"zip" valid:"optional" default:"00000"`
+}
+
+type xmlParam struct {
+	XMLName  xml.Name          `xml:"order"`
+	ID       int               `xml:"id,attr" valid:"required"`
+	Name     string            `xml:"name" valid:"required" min_len:"2"`
+	Greeting string            `xml:"greeting" valid:"optional" default:"hello"`
+	Since    time.Time         `xml:"since" valid:"optional" time_format:"2006-01-02"`
+	Limit    *int              `xml:"limit" valid:"optional"`
+	Tags     []string          `xml:"tag" valid:"optional" max_items:"2"`
+	Meta     map[string]string `xml:"meta" valid:"optional" keys:"color|size"`
+	Address  xmlAddress        `xml:"address"`
+	Items    []xmlItem         `xml:"item" valid:"optional" min_items:"1"`
+	Phone    string            `xml:"phone" valid:"required_with=email"`
+	Email    string            `xml:"email" valid:"optional"`
+}
+
+func TestXml(t *testing.T) {
+	body := `<?xml version="1.0"?>
+<order id="7">
+	<name>Tony</name>
+	<since>2020-02-03</since>
+	<tag>go</tag>
+	<tag>xml</tag>
+	<meta><color>red</color></meta>
+	<address><city>Paris</city></address>
+	<item sku="a"><qty>2</qty></item>
+	<item sku="b"><qty>3</qty></item>
+</order>`
+
+	for _, contentType := range []string{ContentTypeXml, ContentTypeTextXml} {
+		obj := xmlParam{}
+		req := request("POST", "/", body, contentType)
+		assert.NoError(t, Bind(req, &obj))
+		assert.Equal(t, "order", obj.XMLName.Local)
+		assert.Equal(t, 7, obj.ID)
+		assert.Equal(t, "Tony", obj.Name)
+		assert.Equal(t, "hello", obj.Greeting)
+		assert.Equal(t, time.Date(2020, 2, 3, 0, 0, 0, 0, time.UTC), obj.Since)
+		assert.Nil(t, obj.Limit)
+		assert.Equal(t, []string{"go", "xml"}, obj.Tags)
+		assert.Equal(t, map[string]string{"color": "red"}, obj.Meta)
+		assert.Equal(t, xmlAddress{City: "Paris", Zip: "00000"}, obj.Address)
+		assert.Equal(t, []xmlItem{{"a", 2}, {"b", 3}}, obj.Items)
+	}
+}
+
+func TestXmlErrors(t *testing.T) {
+	body := `<order>
+	<name>T</name>
+	<limit>many</limit>
+	<tag>a</tag><tag>b</tag><tag>c</tag>
+	<meta><weight>1</weight></meta>
+	<address><zip>1</zip></address>
+	<email>a@b.c</email>
+</order>`
+	req := request("POST", "/", body, ContentTypeXml)
+	err := Bind(req, &xmlParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "id: not found; name: shorter than 2 characters; limit: int expected; "+
+		"tag: more than 2 items; meta.weight: key weight is not in [color size]; address.city: not found; "+
+		"phone: not found, required with email", err.Error())
+	assert.True(t, errors.Is(err, CodeParamNotFound))
+}
+
+func TestXmlMalformed(t *testing.T) {
+	req := request("POST", "/", `<order><name>Tony</order>`, ContentTypeXml)
+	err := Bind(req, &xmlParam{})
+	assert.True(t, errors.Is(err, ErrDecodeXml))
+
+	req = request("POST", "/", `<invoice id="1"><name>Tony</name></invoice>`, ContentTypeXml)
+	err = Bind(req, &xmlParam{})
+	assert.True(t, errors.Is(err, ErrDecodeXml))
+}
+
+func TestXmlRecursive(t *testing.T) {
+	obj := nodeParam{}
+	req := request("POST", "/", `<node><name>x</name></node>`, ContentTypeXml)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "x", obj.Name)
+	assert.Nil(t, obj.Child)
+
+	obj = nodeParam{}
+	req = request("POST", "/", `<node><name>x</name><child><name>y</name></child></node>`, ContentTypeXml)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "y", obj.Child.Name)
+	assert.Nil(t, obj.Child.Child)
+}
+
+func TestXmlItemsStructs(t *testing.T) {
+	obj := orderParam{}
+	req := request("POST", "/", `<order><item sku="a"/><item sku="b"><qty>2</qty></item></order>`, ContentTypeXml)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, []orderLine{{"a", 1}, {"b", 2}}, obj.Items)
+
+	req = request("POST", "/", `<order><item><qty>0</qty></item><extra sku="c"><qty>x</qty></extra></order>`,
+		ContentTypeXml)
+	err := Bind(req, &orderParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "item.0.sku: not found; item.0.qty: smaller than 1; extra.0.qty: int expected", err.Error())
+}
+
+type xmlParentParam struct {
+	Name  string   `xml:"name" valid:"required"`
+	City  string   `xml:"address>city" valid:"required"`
+	Zip   int      `xml:"address>zip" valid:"optional" default:"10000" min:"10000"`
+	Phone []string `xml:"contact>phone" valid:"optional" max_items:"2"`
+	Email string   `xml:"contact>email" valid:"required_with=Phone"`
+}
+
+func TestXmlParentElements(t *testing.T) {
+	obj := xmlParentParam{}
+	body := `<user><name>Tony</name><address><city>Paris</city></address>` +
+		`<contact><phone>1</phone><phone>2</phone><email>a@b.c</email></contact></user>`
+	req := request("POST", "/", body, ContentTypeXml)
+	assert.NoError(t, Bind(req, &obj))
+	assert.Equal(t, "Paris", obj.City)
+	assert.Equal(t, 10000, obj.Zip)
+	assert.Equal(t, []string{"1", "2"}, obj.Phone)
+	assert.Equal(t, "a@b.c", obj.Email)
+
+	body = `<user><name>Tony</name><address><zip>1</zip></address><contact><phone>1</phone></contact></user>`
+	req = request("POST", "/", body, ContentTypeXml)
+	err := Bind(req, &xmlParentParam{})
+	assert.Error(t, err)
+	assert.Equal(t, "address.city: not found; address.zip: smaller than 10000; "+
+		"contact.email: not found, required with contact.phone", err.Error())
+}
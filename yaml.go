@@ -0,0 +1,115 @@
+package validator
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlDocument returns the document of the yaml body root, or nil for an
+// empty or null one. An alias shares the values of its anchor rather than
+// expanding them, and binding the document is bounded by an aliasBudget.
+func yamlDocument(root *yaml.Node) *document {
+	c := &yamlConverter{anchors: make(map[*yaml.Node]*document), budget: &aliasBudget{}}
+	return c.document(root, 0)
+}
+
+// yamlConverter converts the nodes of a yaml body, remembering the document
+// of every anchored node.
+type yamlConverter struct {
+	anchors map[*yaml.Node]*document
+	budget  *aliasBudget
+}
+
+// document returns the value of the yaml node n, whose key is at line, or nil
+// for null.
+func (c *yamlConverter) document(n *yaml.Node, line int) *document {
+	if n.Kind == yaml.AliasNode {
+		anchor, ok := c.anchors[n.Alias]
+		if !ok {
+			anchor = c.document(n.Alias, line)
+		}
+		if anchor == nil {
+			return nil
+		}
+		// Only the line of the alias is its own
+		alias := *anchor
+		alias.line = line
+		return &alias
+	}
+	doc := c.convert(n, line)
+	if n.Anchor != "" {
+		c.anchors[n] = doc
+	}
+	return doc
+}
+
+func (c *yamlConverter) convert(n *yaml.Node, line int) *document {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return c.document(n.Content[0], line)
+	case yaml.ScalarNode:
+		if n.ShortTag() == "!!null" {
+			return nil
+		}
+		value := n.Value
+		doc := c.new(line)
+		doc.scalar = &value
+		switch n.ShortTag() {
+		case "!!int", "!!float", "!!bool":
+			var v interface{}
+			if err := n.Decode(&v); err == nil {
+				resolved := fmt.Sprint(v)
+				doc.resolved = &resolved
+			}
+		}
+		return doc
+	case yaml.MappingNode:
+		doc := c.new(line)
+		doc.fields = make(map[string]*document, len(n.Content)/2)
+		var merged []*document
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i]
+			value := c.document(n.Content[i+1], key.Line)
+			if key.ShortTag() == "!!merge" {
+				// Such as `<<: *base` or `<<: [*base, *other]`
+				if value != nil && value.fields == nil {
+					merged = append(merged, value.items...)
+				} else {
+					merged = append(merged, value)
+				}
+				continue
+			}
+			doc.fields[key.Value] = value
+		}
+		// Keys of the mapping override merged ones, and the first merged
+		// mapping overrides the next ones
+		for _, m := range merged {
+			if m == nil {
+				continue
+			}
+			for key, value := range m.fields {
+				if _, ok := doc.fields[key]; !ok {
+					doc.fields[key] = value
+				}
+			}
+		}
+		return doc
+	default:
+		doc := c.new(line)
+		doc.items = make([]*document, 0, len(n.Content))
+		for _, item := range n.Content {
+			doc.items = append(doc.items, c.document(item, item.Line))
+		}
+		return doc
+	}
+}
+
+// new returns an empty document at line, counted as a node of the body.
+func (c *yamlConverter) new(line int) *document {
+	c.budget.nodes++
+	return &document{line: line, budget: c.budget}
+}